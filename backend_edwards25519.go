@@ -0,0 +1,61 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+//go:build edwards25519
+// +build edwards25519
+
+package bw2crypto
+
+const haveBackend = true
+
+//SignVector will generate a signature on the arguments, in order
+//and write it into the 64 byte into
+func SignVector(sk []byte, vk []byte, into []byte, vec ...[]byte) error {
+	return edSignVector(sk, vk, into, vec...)
+}
+
+//SignBlob writes the signature over blob into the 64 byte into
+func SignBlob(sk []byte, vk []byte, into []byte, blob []byte) error {
+	return edSignVector(sk, vk, into, blob)
+}
+
+//VerifyBlob returns true if the sig is ok, false otherwise
+func VerifyBlob(vk []byte, sig []byte, blob []byte) bool {
+	return edVerifyVector(vk, sig, blob)
+}
+
+//VerifyVector returns true if sig is a signature made by SignVector
+//over the same arguments, in the same order
+func VerifyVector(vk []byte, sig []byte, vec ...[]byte) bool {
+	return edVerifyVector(vk, sig, vec...)
+}
+
+func verifyHram(vk []byte, sig []byte, hram []byte) bool {
+	return edVerifyHram(vk, sig, hram)
+}
+
+func batchVerifyBlob(vks [][]byte, sigs [][]byte, blobs [][]byte) []bool {
+	return loopVerifyBlob(VerifyBlob, vks, sigs, blobs)
+}
+
+func GenerateKeypair() (sk []byte, vk []byte) {
+	return generateKeypairWith(edPublicKey)
+}
+
+func publicKey(sk []byte) []byte {
+	return edPublicKey(sk)
+}
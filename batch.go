@@ -0,0 +1,87 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"errors"
+	"time"
+)
+
+//ErrBatchLengthMismatch is returned by BatchVerifyBlob if vks, sigs and
+//blobs are not all the same length
+var ErrBatchLengthMismatch = errors.New("vks, sigs and blobs must be the same length")
+
+//BatchVerifyBlob verifies blobs[i] against sigs[i] and vks[i] for every
+//i and reports each result separately. Every result is the one VerifyBlob
+//gives for that item, so an invalid signature never affects the result
+//of its neighbours. Items with a malformed key or signature, including
+//an S that is not reduced mod L, are false and are not passed to the
+//backend.
+func BatchVerifyBlob(vks [][]byte, sigs [][]byte, blobs [][]byte) ([]bool, error) {
+	if len(vks) != len(sigs) || len(sigs) != len(blobs) {
+		return nil, ErrBatchLengthMismatch
+	}
+	if err := Supported(); err != nil {
+		return nil, err
+	}
+	var wellFormed []int
+	for i := range vks {
+		if len(vks[i]) == 32 && len(sigs[i]) == 64 && canonicalS(sigs[i]) {
+			wellFormed = append(wellFormed, i)
+		}
+	}
+	if len(wellFormed) == len(vks) {
+		return batchVerifyBlob(vks, sigs, blobs), nil
+	}
+	fvks := make([][]byte, len(wellFormed))
+	fsigs := make([][]byte, len(wellFormed))
+	fblobs := make([][]byte, len(wellFormed))
+	for j, i := range wellFormed {
+		fvks[j], fsigs[j], fblobs[j] = vks[i], sigs[i], blobs[i]
+	}
+	rv := make([]bool, len(vks))
+	for j, ok := range batchVerifyBlob(fvks, fsigs, fblobs) {
+		rv[wellFormed[j]] = ok
+	}
+	return rv, nil
+}
+
+//BatchStats describes one call to VerifyBatchStats
+type BatchStats struct {
+	Count        int
+	Total        time.Duration
+	PerSignature time.Duration
+}
+
+//VerifyBatchStats verifies blobs[i] against sigs[i] and vks[i] and also
+//reports how long it took. The clock is only read before and after the
+//whole batch so timing adds no per signature overhead. It returns the
+//same errors as BatchVerifyBlob, with zero stats.
+func VerifyBatchStats(vks [][]byte, sigs [][]byte, blobs [][]byte) (results []bool, stats BatchStats, err error) {
+	start := time.Now()
+	results, err = BatchVerifyBlob(vks, sigs, blobs)
+	if err != nil {
+		return nil, BatchStats{}, err
+	}
+	stats.Total = time.Since(start)
+	stats.Count = len(vks)
+	if stats.Count > 0 {
+		stats.PerSignature = stats.Total / time.Duration(stats.Count)
+	}
+	return results, stats, nil
+}
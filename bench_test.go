@@ -1,7 +1,8 @@
-package main
+package bw2crypto
 
 import (
 	"crypto/rand"
+	"fmt"
 	"testing"
 )
 
@@ -90,3 +91,148 @@ func BenchmarkVerify(b *testing.B) {
 		}
 	}
 }
+
+//BenchmarkBatchVerifyBlob checks the same 256 signatures as
+//BenchmarkVerify with a single BatchVerifyBlob call per iteration
+func BenchmarkBatchVerifyBlob(b *testing.B) {
+	const NN = 256
+	targets := make([][]byte, NN)
+	vks := make([][]byte, NN)
+	sigs := make([][]byte, NN)
+	for i := 0; i < NN; i++ {
+		targets[i] = make([]byte, 1*1024)
+		rand.Read(targets[i])
+		var sk []byte
+		sk, vks[i] = GenerateKeypair()
+		sigs[i] = make([]byte, 64)
+		SignBlob(sk, vks[i], sigs[i], targets[i])
+	}
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		res, err := BatchVerifyBlob(vks, sigs, targets)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, ok := range res {
+			if !ok {
+				panic("UH")
+			}
+		}
+	}
+}
+
+/*
+The signing backend is chosen at build time: the cgo ed25519-donna code
+by default, crypto/ed25519 with -tags purego and filippo.io/edwards25519
+with -tags edwards25519. BenchmarkSign/BenchmarkVerify measure whichever
+backend is active, the benchmarks below always measure the pure Go ones
+so a single run compares all three:
+
+	go test -bench .
+*/
+func benchSignWith(b *testing.B, sign func(sk []byte, vk []byte, into []byte, vec ...[]byte) error) {
+	const NN = 256
+	targets := make([][]byte, NN)
+	vks := make([][]byte, NN)
+	sks := make([][]byte, NN)
+	sigs := make([][]byte, NN)
+	for i := 0; i < NN; i++ {
+		targets[i] = make([]byte, 1*1024)
+		rand.Read(targets[i])
+		sks[i], vks[i] = GenerateKeypair()
+		sigs[i] = make([]byte, 64)
+	}
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		for i := 0; i < NN; i++ {
+			sign(sks[i], vks[i], sigs[i], targets[i])
+		}
+	}
+}
+
+func benchVerifyWith(b *testing.B, verify func(vk []byte, sig []byte, blob []byte) bool) {
+	const NN = 256
+	targets := make([][]byte, NN)
+	vks := make([][]byte, NN)
+	sigs := make([][]byte, NN)
+	for i := 0; i < NN; i++ {
+		targets[i] = make([]byte, 1*1024)
+		rand.Read(targets[i])
+		var sk []byte
+		sk, vks[i] = GenerateKeypair()
+		sigs[i] = make([]byte, 64)
+		SignBlob(sk, vks[i], sigs[i], targets[i])
+	}
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		for i := 0; i < NN; i++ {
+			if !verify(vks[i], sigs[i], targets[i]) {
+				panic("UH")
+			}
+		}
+	}
+}
+
+func BenchmarkSignStdlib(b *testing.B) {
+	benchSignWith(b, stdSignVector)
+}
+
+func BenchmarkSignEdwards25519(b *testing.B) {
+	benchSignWith(b, edSignVector)
+}
+
+func BenchmarkVerifyStdlib(b *testing.B) {
+	benchVerifyWith(b, stdVerifyBlob)
+}
+
+func BenchmarkVerifyEdwards25519(b *testing.B) {
+	benchVerifyWith(b, func(vk []byte, sig []byte, blob []byte) bool {
+		return edVerifyVector(vk, sig, blob)
+	})
+}
+
+//vectorShapes are the element counts and element sizes used to compare
+//SignVector against concatenating and calling SignBlob
+var vectorShapes = []struct{ n, size int }{
+	{2, 16}, {8, 16}, {32, 16},
+	{2, 1024}, {8, 1024}, {32, 1024},
+}
+
+func vectorFor(n, size int) [][]byte {
+	vec := make([][]byte, n)
+	for i := range vec {
+		vec[i] = make([]byte, size)
+		rand.Read(vec[i])
+	}
+	return vec
+}
+
+func BenchmarkSignVector(b *testing.B) {
+	sk, vk := GenerateKeypair()
+	sig := make([]byte, 64)
+	for _, s := range vectorShapes {
+		vec := vectorFor(s.n, s.size)
+		b.Run(fmt.Sprintf("n=%d/size=%d", s.n, s.size), func(b *testing.B) {
+			for k := 0; k < b.N; k++ {
+				SignVector(sk, vk, sig, vec...)
+			}
+		})
+	}
+}
+
+func BenchmarkConcatSignBlob(b *testing.B) {
+	sk, vk := GenerateKeypair()
+	sig := make([]byte, 64)
+	for _, s := range vectorShapes {
+		vec := vectorFor(s.n, s.size)
+		b.Run(fmt.Sprintf("n=%d/size=%d", s.n, s.size), func(b *testing.B) {
+			for k := 0; k < b.N; k++ {
+				var blob []byte
+				for _, v := range vec {
+					blob = append(blob, v...)
+				}
+				SignBlob(sk, vk, sig, blob)
+			}
+		})
+	}
+}
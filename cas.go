@@ -0,0 +1,67 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+)
+
+//Multihash codes (https://github.com/multiformats/multicodec). Both fit
+//in a single varint byte, as do their digest lengths.
+const (
+	multihashSHA256 = 0x12
+	multihashSHA512 = 0x13
+)
+
+//SignCAS returns the SHA-512 multihash of data, to use as its content
+//address, along with the signature over data. Both are nil if the keys
+//are malformed.
+func SignCAS(sk []byte, vk []byte, data []byte) (cid []byte, sig []byte) {
+	sig = make([]byte, 64)
+	if SignBlob(sk, vk, sig, data) != nil {
+		return nil, nil
+	}
+	sum := sha512.Sum512(data)
+	cid = append([]byte{multihashSHA512, 64}, sum[:]...)
+	return cid, sig
+}
+
+//VerifyCAS returns true if cid is the SHA-512 or SHA-256 multihash of
+//data and sig is vk's signature over data
+func VerifyCAS(vk []byte, cid []byte, sig []byte, data []byte) bool {
+	if len(vk) != 32 || len(sig) != 64 || len(cid) < 2 {
+		return false
+	}
+	var digest []byte
+	switch {
+	case cid[0] == multihashSHA512 && cid[1] == 64:
+		sum := sha512.Sum512(data)
+		digest = sum[:]
+	case cid[0] == multihashSHA256 && cid[1] == 32:
+		sum := sha256.Sum256(data)
+		digest = sum[:]
+	default:
+		return false
+	}
+	if subtle.ConstantTimeCompare(cid[2:], digest) != 1 {
+		return false
+	}
+	return VerifyBlob(vk, sig, data)
+}
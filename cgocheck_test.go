@@ -0,0 +1,33 @@
+//go:build cgo && !purego && !edwards25519
+// +build cgo,!purego,!edwards25519
+
+package bw2crypto
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+//TestCgocheck2 reruns the tests that hand lists of Go buffers to C
+//under GOEXPERIMENT=cgocheck2, which aborts on any unpinned Go pointer
+//stored into C memory. The plain cgocheck can't see those stores.
+func TestCgocheck2(t *testing.T) {
+	if os.Getenv("BW2CRYPTO_CGOCHECK2") != "" {
+		t.Skip("already running under cgocheck2")
+	}
+	if testing.Short() {
+		t.Skip("rebuilds the package, skipped in short mode")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("no go command to rebuild with")
+	}
+	cmd := exec.Command(gobin, "test", "-count=1",
+		"-run", "^(TestBatchVerifyBlob|TestVerifyBatchStats|TestVerifyVectorRoundTrip|TestSignVectorEmptyElements|TestSignBadLengths|TestVerifyHeaderBody)$", ".")
+	cmd.Env = append(os.Environ(), "GOEXPERIMENT=cgocheck2", "BW2CRYPTO_CGOCHECK2=1")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("tests failed under cgocheck2: %v\n%s", err, out)
+	}
+}
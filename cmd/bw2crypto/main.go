@@ -0,0 +1,75 @@
+package main
+
+import (
+  "encoding/hex"
+  "fmt"
+  "os"
+
+  "github.com/samkumar/bw2crypto"
+)
+
+func main() {
+  if len(os.Args) == 3 && os.Args[1] == "validate-dir" {
+    validateDir(os.Args[2])
+    return
+  }
+  if len(os.Args) == 2 && os.Args[1] == "selftest-vectors" {
+    printVectors()
+    return
+  }
+  if len(os.Args) != 3 {
+    fmt.Printf("Usage: %s <signing key> <verifying key>\n", os.Args[0])
+    fmt.Printf("       %s validate-dir <directory of .key files>\n", os.Args[0])
+    fmt.Printf("       %s selftest-vectors\n", os.Args[0])
+    os.Exit(0)
+  }
+  sk, e := bw2crypto.UnFmtKey(os.Args[1])
+  if e != nil {
+    fmt.Printf("Could not unformat signing key: %v\n", e)
+    os.Exit(1)
+  }
+  vk, e := bw2crypto.UnFmtKey(os.Args[2])
+  if e != nil {
+    fmt.Printf("Could not unformat verifying key: %v\n", e)
+    os.Exit(1)
+  }
+  if !bw2crypto.CheckKeypair(sk, vk) {
+    fmt.Println("valid keypair failed to validate")
+  }
+}
+
+func validateDir(dir string) {
+  results, e := bw2crypto.ValidateKeyDir(dir)
+  if e != nil {
+    fmt.Printf("Could not read key directory: %v\n", e)
+    os.Exit(1)
+  }
+  failed := false
+  for _, r := range results {
+    if r.Err != nil {
+      failed = true
+      fmt.Printf("FAIL %s %s: %v\n", r.Path, r.Fingerprint, r.Err)
+    } else {
+      fmt.Printf("OK   %s %s\n", r.Path, r.Fingerprint)
+    }
+  }
+  if failed {
+    os.Exit(1)
+  }
+}
+
+func printVectors() {
+  fmt.Println("# seed message verifying-key signature (hex, empty message is -)")
+  vecs, err := bw2crypto.SelfTestVectors()
+  if err != nil {
+    fmt.Println(err)
+    os.Exit(1)
+  }
+  for _, v := range vecs {
+    msg := hex.EncodeToString(v.Message)
+    if msg == "" {
+      msg = "-"
+    }
+    fmt.Println(hex.EncodeToString(v.Seed), msg, hex.EncodeToString(v.Vk), hex.EncodeToString(v.Sig))
+  }
+}
@@ -0,0 +1,54 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"errors"
+	"sync"
+)
+
+//ErrCompromisedKey is returned by VerifyBlobSafe for keys flagged with
+//MarkCompromised
+var ErrCompromisedKey = errors.New("Key is marked as compromised")
+
+var compromisedLock sync.RWMutex
+var compromisedKeys = make(map[string]struct{})
+
+//MarkCompromised flags vk as leaked for the life of the process
+func MarkCompromised(vk []byte) {
+	compromisedLock.Lock()
+	compromisedKeys[string(vk)] = struct{}{}
+	compromisedLock.Unlock()
+}
+
+//RejectCompromised returns true if vk has been marked as compromised
+func RejectCompromised(vk []byte) bool {
+	compromisedLock.RLock()
+	_, bad := compromisedKeys[string(vk)]
+	compromisedLock.RUnlock()
+	return bad
+}
+
+//VerifyBlobSafe is VerifyBlob, but returns ErrCompromisedKey without
+//checking the signature if vk has been marked as compromised
+func VerifyBlobSafe(vk []byte, sig []byte, blob []byte) (bool, error) {
+	if RejectCompromised(vk) {
+		return false, ErrCompromisedKey
+	}
+	return VerifyBlob(vk, sig, blob), nil
+}
@@ -0,0 +1,62 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+)
+
+//SignConfig returns a signed config file: the formatted signature over
+//body on the first line, followed by body unchanged. It returns nil if
+//the keys are malformed.
+func SignConfig(sk []byte, vk []byte, body []byte) []byte {
+	sig := make([]byte, 64)
+	if SignBlob(sk, vk, sig, body) != nil {
+		return nil
+	}
+	rv := []byte(FmtSig(sig) + "\n")
+	return append(rv, body...)
+}
+
+//LoadSignedConfig reads a file written by SignConfig and returns the
+//body together with whichever of trustedVks signed it. An error is
+//returned if the file is malformed or no trusted key signed it.
+func LoadSignedConfig(path string, trustedVks [][]byte) (contents []byte, signerVk []byte, err error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	nl := bytes.IndexByte(raw, '\n')
+	if nl < 0 {
+		return nil, nil, errors.New("Missing signature header")
+	}
+	sig, err := UnFmtSig(strings.TrimSuffix(string(raw[:nl]), "\r"))
+	if err != nil {
+		return nil, nil, err
+	}
+	body := raw[nl+1:]
+	for _, vk := range trustedVks {
+		if len(vk) == 32 && VerifyBlob(vk, sig, body) {
+			return body, vk, nil
+		}
+	}
+	return nil, nil, errors.New("Config not signed by a trusted key")
+}
@@ -0,0 +1,81 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"crypto/hkdf"
+	"crypto/sha512"
+)
+
+var (
+	messageKeySalt = []byte("bw2crypto message key")
+	messageKeyTag  = []byte("bw2crypto message key endorsement\x00")
+)
+
+//DeriveMessageKey derives a keypair for one message from a master seed
+//with HKDF-SHA-512, using messageID as the info string. Different
+//message IDs give unrelated looking keys.
+func DeriveMessageKey(masterSeed []byte, messageID []byte) (sk []byte, vk []byte, err error) {
+	if err := Supported(); err != nil {
+		return nil, nil, err
+	}
+	sk, err = hkdf.Key(sha512.New, masterSeed, messageKeySalt, string(messageID), 32)
+	if err != nil {
+		//Only possible for lengths above 255 hash blocks
+		panic(err)
+	}
+	return sk, publicKey(sk), nil
+}
+
+func messageKeyBlob(msgVk []byte, messageID []byte) []byte {
+	rv := append([]byte{}, messageKeyTag...)
+	rv = append(rv, msgVk...)
+	return append(rv, messageID...)
+}
+
+//SignWithMessageKey signs blob with the key derived for messageID. A
+//verifier can't recompute that key from the master verifying key, so
+//the master keypair also signs an endorsement binding msgVk to
+//messageID, returned as proof. Anyone holding proof can link the
+//message key to the master. All three are nil if the master keys are
+//malformed.
+func SignWithMessageKey(masterSeed []byte, masterVk []byte, messageID []byte, blob []byte) (msgVk []byte, proof []byte, sig []byte) {
+	msk, msgVk, err := DeriveMessageKey(masterSeed, messageID)
+	if err != nil {
+		return nil, nil, nil
+	}
+	proof = make([]byte, 64)
+	if SignBlob(masterSeed, masterVk, proof, messageKeyBlob(msgVk, messageID)) != nil {
+		return nil, nil, nil
+	}
+	sig = make([]byte, 64)
+	SignBlob(msk, msgVk, sig, blob)
+	return msgVk, proof, sig
+}
+
+//VerifyWithMessageKey checks that masterVk endorsed msgVk for messageID
+//and that sig is msgVk's signature over blob
+func VerifyWithMessageKey(masterVk []byte, messageID []byte, msgVk []byte, proof []byte, sig []byte, blob []byte) bool {
+	if len(masterVk) != 32 || len(msgVk) != 32 || len(proof) != 64 || len(sig) != 64 {
+		return false
+	}
+	if !VerifyBlob(masterVk, proof, messageKeyBlob(msgVk, messageID)) {
+		return false
+	}
+	return VerifyBlob(msgVk, sig, blob)
+}
@@ -0,0 +1,61 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"crypto/sha512"
+	"errors"
+)
+
+//ErrEmptyContext is returned by SignBlobWithContext for an empty
+//context. Use SignBlob for signatures that belong to no subsystem.
+var ErrEmptyContext = errors.New("Context must not be empty")
+
+var domainTagPrefix = []byte("bw2crypto domain\x00")
+
+//domainTag is the 32 byte tag signed ahead of the blob for a context. SignVector adds no framing between elements, so the tag must
+//be fixed length for the context and blob boundary to be unambiguous.
+func domainTag(context string) []byte {
+	h := sha512.New()
+	h.Write(domainTagPrefix)
+	h.Write([]byte(context))
+	return h.Sum(nil)[:32]
+}
+
+//SignBlobWithContext signs blob for use in the subsystem named by
+//context, so the signature does not verify under any other context.
+//The context must not be empty. The signed message is the tag followed
+//by blob, so contexts only separate signatures made through this
+//function: a SignBlob or SignVector caller who chooses the first 32
+//bytes of its message can produce the same signature, and subsystems
+//sharing a key must all use contexts.
+func SignBlobWithContext(sk []byte, vk []byte, into []byte, blob []byte, context string) error {
+	if context == "" {
+		return ErrEmptyContext
+	}
+	return SignVector(sk, vk, into, domainTag(context), blob)
+}
+
+//VerifyBlobWithContext checks a signature from SignBlobWithContext made
+//with the same context. It is always false for an empty context.
+func VerifyBlobWithContext(vk []byte, sig []byte, blob []byte, context string) bool {
+	if context == "" {
+		return false
+	}
+	return VerifyVector(vk, sig, domainTag(context), blob)
+}
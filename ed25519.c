@@ -1,3 +1,6 @@
+//go:build !purego && !edwards25519
+// +build !purego,!edwards25519
+
 /*
 	Public domain by Andrew M. <liquidsun@gmail.com>
 
@@ -19,6 +22,17 @@
 #include "ed25519-randombytes.h"
 #include "ed25519-hash.h"
 
+/*
+	Clears secret intermediates before they go out of scope. The stores
+	go through a volatile pointer so the compiler can't drop them.
+*/
+static void
+bw_wipe(void *p, size_t n) {
+	volatile unsigned char *v = (volatile unsigned char *)p;
+	while (n--)
+		*v++ = 0;
+}
+
 __attribute__((used)) void bw_generate_keypair(unsigned char *private, unsigned char *public)
 {
     ed25519_randombytes_unsafe(private, 32);
@@ -72,6 +86,9 @@ ED25519_FN(ed25519_publickey) (const ed25519_secret_key sk, ed25519_public_key p
 	expand256_modm(a, extsk, 32);
 	ge25519_scalarmult_base_niels(&A, ge25519_niels_base_multiples, a);
 	ge25519_pack(pk, &A);
+
+	bw_wipe(a, sizeof(a));
+	bw_wipe(extsk, sizeof(extsk));
 }
 
 
@@ -108,6 +125,13 @@ ED25519_FN(ed25519_sign) (const unsigned char *m, size_t mlen, const ed25519_sec
 
 	/* S = (r + H(R,A,m)a) mod L */
 	contract256_modm(RS + 32, S);
+
+	bw_wipe(&ctx, sizeof(ctx));
+	bw_wipe(r, sizeof(r));
+	bw_wipe(S, sizeof(S));
+	bw_wipe(a, sizeof(a));
+	bw_wipe(extsk, sizeof(extsk));
+	bw_wipe(hashr, sizeof(hashr));
 }
 
 //Little variant to be able to take message in parts
@@ -148,6 +172,13 @@ ED25519_FN(ed25519_sign_vector) (const unsigned char **ms, size_t *mlens, size_t
 
 	/* S = (r + H(R,A,m)a) mod L */
 	contract256_modm(RS + 32, S);
+
+	bw_wipe(&ctx, sizeof(ctx));
+	bw_wipe(r, sizeof(r));
+	bw_wipe(S, sizeof(S));
+	bw_wipe(a, sizeof(a));
+	bw_wipe(extsk, sizeof(extsk));
+	bw_wipe(hashr, sizeof(hashr));
 }
 
 
@@ -201,6 +232,31 @@ ED25519_FN(ed25519_sign_open_vector) (const unsigned char **ms, size_t *mlens, s
 	return ed25519_verify(RS, checkR, 32) ? 0 : -1;
 }
 
+/*
+	ed25519_sign_open with hram = H(R,A,m) computed by the caller, so the
+	message can be hashed in a streaming fashion on the Go side
+*/
+__attribute__((used)) int
+bw_sign_open_hram(const unsigned char *hash, const ed25519_public_key pk, const ed25519_signature RS) {
+	ge25519 ALIGN(16) R, A;
+	bignum256modm hram, S;
+	unsigned char checkR[32];
+
+	if ((RS[63] & 224) || !ge25519_unpack_negative_vartime(&A, pk))
+		return -1;
+
+	expand256_modm(hram, hash, 64);
+
+	/* S */
+	expand256_modm(S, RS + 32, 32);
+
+	/* SB - H(R,A,m)A */
+	ge25519_double_scalarmult_vartime(&R, &A, hram, S);
+	ge25519_pack(checkR, &R);
+
+	/* check that R = SB - H(R,A,m)A */
+	return ed25519_verify(RS, checkR, 32) ? 0 : -1;
+}
 
 #include "ed25519-donna-batchverify.h"
 
@@ -15,7 +15,10 @@
 //
 // Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
 
-package main
+//go:build !purego && !edwards25519
+// +build !purego,!edwards25519
+
+package bw2crypto
 
 // #cgo CFLAGS: -O2
 // #cgo linux LDFLAGS: -lssl -lcrypto
@@ -28,13 +31,14 @@ import "C"
 import (
 	"crypto/rand"
 	"crypto/sha512"
-	"encoding/base64"
-	"errors"
 	"hash"
+	"runtime"
 	"sync"
 	"unsafe"
 )
 
+const haveBackend = true
+
 //These functions are used on windows by the C so we don't have to link to openSSL
 
 var hashCtxLock sync.Mutex
@@ -88,12 +92,16 @@ func RandomBytes(dest *C.uint8_t, ln C.size_t) {
 	C.memcpy(unsafe.Pointer(dest), unsafe.Pointer(&rv[0]), ln)
 }
 
-//SignVector will generate a signature on the arguments, in order
-//and return it
-func SignVector(sk []byte, vk []byte, into []byte, vec ...[]byte) {
-	if len(into) != 64 {
-		panic("Into must be exactly 64 bytes long")
-	}
+//emptyField stands in for zero length elements of a vector, which have
+//no &v[0] to hand to C. The C side is given length 0 and never reads it.
+var emptyField [1]byte
+
+//cVector builds the char** list and lengths array the C vector
+//functions take. The list holds Go pointers, which the cgo rules only
+//allow in C memory while pinned, so every buffer is pinned with pin
+//before it is stored. The caller unpins once C is done with the list,
+//and releases the list with freeCVector.
+func cVector(pin *runtime.Pinner, vec [][]byte) (unsafe.Pointer, []C.size_t) {
 	lens := make([]C.size_t, len(vec))
 	for i, v := range vec {
 		lens[i] = C.size_t(len(v))
@@ -104,13 +112,41 @@ func SignVector(sk []byte, vk []byte, into []byte, vec ...[]byte) {
 
 	// Allocate the char** list.
 	ptr := C.malloc(C.size_t(len(vec)) * C.size_t(ptrSize))
-	defer C.free(ptr)
 
 	// Assign each byte slice to its appropriate offset.
 	for i := 0; i < len(vec); i++ {
 		element := (**C.char)(unsafe.Pointer(uintptr(ptr) + uintptr(i)*ptrSize))
-		*element = (*C.char)(unsafe.Pointer(&vec[i][0]))
+		p := &emptyField[0]
+		if len(vec[i]) != 0 {
+			p = &vec[i][0]
+		}
+		pin.Pin(p)
+		*element = (*C.char)(unsafe.Pointer(p))
 	}
+	return ptr, lens
+}
+
+//freeCVector clears a list from cVector before releasing it, so the
+//pointers into our buffers don't linger in the C heap
+func freeCVector(ptr unsafe.Pointer, n int) {
+	var b *C.char
+	C.memset(ptr, 0, C.size_t(n)*C.size_t(unsafe.Sizeof(b)))
+	C.free(ptr)
+}
+
+//SignVector will generate a signature on the arguments, in order
+//and write it into the 64 byte into
+func SignVector(sk []byte, vk []byte, into []byte, vec ...[]byte) error {
+	if err := checkSignArgs(sk, vk, into); err != nil {
+		return err
+	}
+	if len(vec) == 0 {
+		return SignBlob(sk, vk, into, nil)
+	}
+	var pin runtime.Pinner
+	defer pin.Unpin()
+	ptr, lens := cVector(&pin, vec)
+	defer freeCVector(ptr, len(vec))
 
 	C.ed25519_sign_vector((**C.uchar)(ptr),
 		(*C.size_t)(unsafe.Pointer(&lens[0])),
@@ -118,28 +154,86 @@ func SignVector(sk []byte, vk []byte, into []byte, vec ...[]byte) {
 		(*C.uchar)(unsafe.Pointer(&sk[0])),
 		(*C.uchar)(unsafe.Pointer(&vk[0])),
 		(*C.uchar)(unsafe.Pointer(&into[0])))
+	return nil
+}
+
+//VerifyVector returns true if sig is a signature made by SignVector
+//over the same arguments, in the same order
+func VerifyVector(vk []byte, sig []byte, vec ...[]byte) bool {
+	if len(vk) != 32 || len(sig) != 64 || !canonicalS(sig) {
+		return false
+	}
+	if len(vec) == 0 {
+		return VerifyBlob(vk, sig, nil)
+	}
+	var pin runtime.Pinner
+	defer pin.Unpin()
+	ptr, lens := cVector(&pin, vec)
+	defer freeCVector(ptr, len(vec))
+
+	rv := C.ed25519_sign_open_vector((**C.uchar)(ptr),
+		(*C.size_t)(unsafe.Pointer(&lens[0])),
+		(C.size_t)(len(vec)),
+		(*C.uchar)(unsafe.Pointer(&vk[0])),
+		(*C.uchar)(unsafe.Pointer(&sig[0])))
+	return rv == 0
 }
 
-func SignBlob(sk []byte, vk []byte, into []byte, blob []byte) {
-	if len(into) != 64 {
-		panic("into must be exactly 64 bytes long")
+//blobPtr returns a pointer to the start of blob, or nil if it is empty
+//in which case the C code hashes nothing for it
+func blobPtr(blob []byte) *C.uchar {
+	if len(blob) == 0 {
+		return nil
 	}
-	C.ed25519_sign((*C.uchar)(unsafe.Pointer(&blob[0])),
+	return (*C.uchar)(unsafe.Pointer(&blob[0]))
+}
+
+//SignBlob writes the signature over blob into the 64 byte into
+func SignBlob(sk []byte, vk []byte, into []byte, blob []byte) error {
+	if err := checkSignArgs(sk, vk, into); err != nil {
+		return err
+	}
+	C.ed25519_sign(blobPtr(blob),
 		(C.size_t)(len(blob)),
 		(*C.uchar)(unsafe.Pointer(&sk[0])),
 		(*C.uchar)(unsafe.Pointer(&vk[0])),
 		(*C.uchar)(unsafe.Pointer(&into[0])))
+	return nil
 }
 
-//VerifyBlob returns true if the sig is ok, false otherwise
+//VerifyBlob returns true if the sig is ok, false otherwise. Like the
+//pure Go backends it rejects an S that is not reduced mod L.
 func VerifyBlob(vk []byte, sig []byte, blob []byte) bool {
-	rv := C.ed25519_sign_open((*C.uchar)(unsafe.Pointer(&blob[0])),
+	if len(vk) != 32 || len(sig) != 64 || !canonicalS(sig) {
+		return false
+	}
+	rv := C.ed25519_sign_open(blobPtr(blob),
 		(C.size_t)(len(blob)),
 		(*C.uchar)(unsafe.Pointer(&vk[0])),
 		(*C.uchar)(unsafe.Pointer(&sig[0])))
 	return rv == 0
 }
 
+//verifyHram checks sig given the 64 byte hram = H(R,A,m), with the same
+//checks as VerifyBlob
+func verifyHram(vk []byte, sig []byte, hram []byte) bool {
+	if len(vk) != 32 || len(sig) != 64 || len(hram) != 64 || !canonicalS(sig) {
+		return false
+	}
+	rv := C.bw_sign_open_hram((*C.uchar)(unsafe.Pointer(&hram[0])),
+		(*C.uchar)(unsafe.Pointer(&vk[0])),
+		(*C.uchar)(unsafe.Pointer(&sig[0])))
+	return rv == 0
+}
+
+//batchVerifyBlob checks every item with VerifyBlob. The donna batch
+//verifier is not used: its random linear combination skips the S range
+//check and ignores small order components of R, so it accepts some
+//signatures that VerifyBlob rejects.
+func batchVerifyBlob(vks [][]byte, sigs [][]byte, blobs [][]byte) []bool {
+	return loopVerifyBlob(VerifyBlob, vks, sigs, blobs)
+}
+
 func GenerateKeypair() (sk []byte, vk []byte) {
 	sk = make([]byte, 32)
 	vk = make([]byte, 32)
@@ -152,44 +246,10 @@ func GenerateKeypair() (sk []byte, vk []byte) {
 	}
 }
 
-func CheckKeypair(sk []byte, vk []byte) bool {
-	blob := make([]byte, 128)
-	rand.Read(blob)
-	sig := make([]byte, 64)
-	SignBlob(sk, vk, sig, blob)
-	return VerifyBlob(vk, sig, blob)
-}
-
-func FmtKey(key []byte) string {
-	return base64.URLEncoding.EncodeToString(key)
-}
-
-func UnFmtKey(key string) ([]byte, error) {
-	rv, err := base64.URLEncoding.DecodeString(key)
-	if len(rv) != 32 {
-		return nil, errors.New("Invalid length")
-	}
-	return rv, err
-}
-
-func FmtSig(sig []byte) string {
-	return base64.URLEncoding.EncodeToString(sig)
-}
-func UnFmtSig(sig string) ([]byte, error) {
-	rv, err := base64.URLEncoding.DecodeString(sig)
-	if len(rv) != 64 {
-		return nil, errors.New("Invalid length")
-	}
-	return rv, err
-}
-
-func FmtHash(hash []byte) string {
-	return base64.URLEncoding.EncodeToString(hash)
-}
-func UnFmtHash(hash string) ([]byte, error) {
-	rv, err := base64.URLEncoding.DecodeString(hash)
-	if len(rv) != 32 {
-		return nil, errors.New("Invalid length")
-	}
-	return rv, err
+//publicKey computes the verifying key for a 32 byte signing key
+func publicKey(sk []byte) []byte {
+	vk := make([]byte, 32)
+	C.ed25519_publickey((*C.uchar)(unsafe.Pointer(&sk[0])),
+		(*C.uchar)(unsafe.Pointer(&vk[0])))
+	return vk
 }
@@ -23,6 +23,8 @@ void ed25519_publickey(const ed25519_secret_key sk, ed25519_public_key pk);
 int ed25519_sign_open(const unsigned char *m, size_t mlen, const ed25519_public_key pk, const ed25519_signature RS);
 void ed25519_sign(const unsigned char *m, size_t mlen, const ed25519_secret_key sk, const ed25519_public_key pk, ed25519_signature RS);
 void ed25519_sign_vector (const unsigned char **ms, size_t *mlens, size_t vlen, const ed25519_secret_key sk, const ed25519_public_key pk, ed25519_signature RS);
+int ed25519_sign_open_vector(const unsigned char **ms, size_t *mlens, size_t vlen, const ed25519_public_key pk, const ed25519_signature RS);
+int bw_sign_open_hram(const unsigned char *hram, const ed25519_public_key pk, const ed25519_signature RS);
 int ed25519_sign_open_batch(const unsigned char **m, size_t *mlen, const unsigned char **pk, const unsigned char **RS, size_t num, int *valid);
 
 void ed25519_randombytes_unsafe(void *out, size_t count);
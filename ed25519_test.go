@@ -0,0 +1,1096 @@
+package bw2crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	mrand "math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"filippo.io/edwards25519"
+)
+
+func TestMain(m *testing.M) {
+	if err := Supported(); err != nil {
+		//Only the tests of the stub itself can run
+		fmt.Println("skipping tests:", err)
+		flag.Parse()
+		flag.Set("test.run", "^TestUnsupported")
+	}
+	os.Exit(m.Run())
+}
+
+//TestStdlibCrossCheck signs random messages under random seeds with the
+//C implementation and verifies them with crypto/ed25519, and vice versa.
+//Any disagreement means the two are no longer byte compatible.
+func TestStdlibCrossCheck(t *testing.T) {
+	const NN = 500
+	for i := 0; i < NN; i++ {
+		sk := make([]byte, 32)
+		rand.Read(sk)
+		msg := make([]byte, 1+mrand.Intn(4096))
+		rand.Read(msg)
+
+		std := ed25519.NewKeyFromSeed(sk)
+		vk := std.Public().(ed25519.PublicKey)
+
+		sig := make([]byte, 64)
+		SignBlob(sk, vk, sig, msg)
+		if !ed25519.Verify(vk, msg, sig) {
+			t.Fatalf("stdlib rejected cgo signature (seed %x, msg %x)", sk, msg)
+		}
+		stdsig := ed25519.Sign(std, msg)
+		if string(stdsig) != string(sig) {
+			t.Fatalf("signature mismatch (seed %x, msg %x): cgo %x std %x", sk, msg, sig, stdsig)
+		}
+		if !VerifyBlob(vk, stdsig, msg) {
+			t.Fatalf("cgo rejected stdlib signature (seed %x, msg %x)", sk, msg)
+		}
+
+		msg[mrand.Intn(len(msg))] ^= 0x01
+		if VerifyBlob(vk, sig, msg) != ed25519.Verify(vk, msg, sig) {
+			t.Fatalf("implementations disagree on tampered message (seed %x)", sk)
+		}
+	}
+}
+
+//TestStdKeyConversion converts keypairs to and from crypto/ed25519 and
+//checks signatures made on either side verify on the other
+func TestStdKeyConversion(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		sk, vk := GenerateKeypair()
+		pub, err := ToStdPublicKey(vk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		priv, err := ToStdPrivateKey(sk, vk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := make([]byte, mrand.Intn(1024))
+		rand.Read(msg)
+
+		sig := make([]byte, 64)
+		SignBlob(sk, vk, sig, msg)
+		if !ed25519.Verify(pub, msg, sig) {
+			t.Fatalf("ed25519.Verify rejected SignBlob (seed %x, msg %x, sig %x)", sk, msg, sig)
+		}
+		stdsig := ed25519.Sign(priv, msg)
+		if !VerifyBlob(vk, stdsig, msg) {
+			t.Fatalf("VerifyBlob rejected ed25519.Sign (seed %x, msg %x, sig %x)", sk, msg, stdsig)
+		}
+
+		sk2, vk2, err := FromStdPrivateKey(priv)
+		if err != nil || !bytes.Equal(sk2, sk) || !bytes.Equal(vk2, vk) {
+			t.Fatalf("private key did not round trip: %v", err)
+		}
+		vk3, err := FromStdPublicKey(pub)
+		if err != nil || !bytes.Equal(vk3, vk) {
+			t.Fatalf("public key did not round trip: %v", err)
+		}
+	}
+
+	sk, vk := TestKeypair("std")
+	_, other := TestKeypair("other")
+	if _, err := ToStdPrivateKey(sk, other); err != ErrKeypairMismatch {
+		t.Fatalf("expected ErrKeypairMismatch, got %v", err)
+	}
+	if _, err := ToStdPublicKey(vk[:31]); err != ErrInvalidKeyLength {
+		t.Fatalf("expected ErrInvalidKeyLength, got %v", err)
+	}
+	bad := append(append([]byte{}, sk...), other...)
+	if _, _, err := FromStdPrivateKey(bad); err != ErrKeypairMismatch {
+		t.Fatalf("expected ErrKeypairMismatch, got %v", err)
+	}
+}
+
+//sameKeyError is true if a and b are the same error, or both base64
+//errors
+func sameKeyError(a error, b error) bool {
+	_, acorrupt := a.(base64.CorruptInputError)
+	_, bcorrupt := b.(base64.CorruptInputError)
+	return a == b || acorrupt && bcorrupt
+}
+
+func TestValidateKey(t *testing.T) {
+	_, vk := TestKeypair("validate")
+	good := FmtKey(vk)
+	dash := ""
+	for i := 0; dash == ""; i++ {
+		_, k := TestKeypair(fmt.Sprintf("dash %d", i))
+		if s := FmtKey(k); s[0] == '-' {
+			dash = s
+		}
+	}
+	//The last data character of a 32 byte key carries 2 unused bits, which
+	//are zero in canonical encodings. Setting one decodes to the same key.
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	last := strings.IndexByte(alphabet, good[42]) | 1
+	loose := good[:42] + alphabet[last:last+1] + "="
+
+	var corrupt base64.CorruptInputError
+	cases := []struct {
+		name string
+		s    string
+		want error
+	}{
+		{"valid", good, nil},
+		{"truncated", good[:41], &corrupt},
+		{"truncated to a whole quantum", good[:40], ErrInvalidKeyLength},
+		{"short but well padded", FmtKey(vk[:31]), ErrInvalidKeyLength},
+		{"long", FmtKey(append(vk, 0)), ErrInvalidKeyLength},
+		{"not base64", "this is not a base64 key!!!!!!!!!!!!!!!!!!!=", &corrupt},
+		{"std alphabet", good[:10] + "+" + good[11:], &corrupt},
+		{"trailing newline", good + "\n", &corrupt},
+		{"trailing space", good + " ", &corrupt},
+		{"missing padding", good[:43], &corrupt},
+		{"extra padding", good + "=", &corrupt},
+		{"non canonical bits", loose, &corrupt},
+		{"empty", "", ErrInvalidKeyLength},
+		{"dash prefixed", dash, ErrDashPrefixedKey},
+	}
+	for _, c := range cases {
+		err := ValidateKey(c.s)
+		//UnFmtKey is just as strict, but accepts dash prefixed keys
+		if _, uerr := UnFmtKey(c.s); c.want != ErrDashPrefixedKey && !sameKeyError(uerr, err) {
+			t.Errorf("%s: UnFmtKey returned %v, ValidateKey %v", c.name, uerr, err)
+		}
+		switch want := c.want.(type) {
+		case nil:
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", c.name, err)
+			}
+		case *base64.CorruptInputError:
+			if _, ok := err.(base64.CorruptInputError); !ok {
+				t.Errorf("%s: expected a base64 error, got %v", c.name, err)
+			}
+		default:
+			if err != want {
+				t.Errorf("%s: expected %v, got %v", c.name, want, err)
+			}
+		}
+	}
+
+	if _, err := UnFmtKey(good[:41]); err == nil || err.Error() == "Invalid length" {
+		t.Fatalf("UnFmtKey masked the decode error: %v", err)
+	}
+	if rv, err := UnFmtKey(dash); err != nil || len(rv) != 32 {
+		t.Fatalf("UnFmtKey rejected a dash prefixed key: %v", err)
+	}
+}
+
+func TestFmtKeyQR(t *testing.T) {
+	_, vk := GenerateKeypair()
+	s := FmtKeyQR(vk)
+	for _, c := range s {
+		if !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') {
+			t.Fatalf("character %q is not QR alphanumeric", c)
+		}
+	}
+	rv, err := UnFmtKeyQR(s)
+	if err != nil || string(rv) != string(vk) {
+		t.Fatalf("round trip failed: %v", err)
+	}
+	bad := []byte(s)
+	if bad[0] == 'A' {
+		bad[0] = 'B'
+	} else {
+		bad[0] = 'A'
+	}
+	if _, err := UnFmtKeyQR(string(bad)); err == nil {
+		t.Fatal("corrupted key decoded without error")
+	}
+	if _, err := UnFmtKeyQR(s[:len(s)-8]); err == nil {
+		t.Fatal("truncated key decoded without error")
+	}
+}
+
+func TestTestKeypair(t *testing.T) {
+	sk1, vk1 := TestKeypair("alice")
+	sk2, vk2 := TestKeypair("alice")
+	if string(sk1) != string(sk2) || string(vk1) != string(vk2) {
+		t.Fatal("same label produced different keypairs")
+	}
+	sk3, _ := TestKeypair("bob")
+	if string(sk1) == string(sk3) {
+		t.Fatal("different labels produced the same signing key")
+	}
+	if !CheckKeypair(sk1, vk1) {
+		t.Fatal("fixture keypair does not validate")
+	}
+	std := ed25519.NewKeyFromSeed(sk1)
+	if string(std.Public().(ed25519.PublicKey)) != string(vk1) {
+		t.Fatal("fixture verifying key differs from crypto/ed25519")
+	}
+}
+
+func TestDeriveVerifyingKey(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		sk, vk := GenerateKeypair()
+		derived, err := DeriveVerifyingKey(sk)
+		if err != nil || !bytes.Equal(derived, vk) {
+			t.Fatalf("derived key differs from generated key: %v", err)
+		}
+		_, other := GenerateKeypair()
+		if CheckKeypair(sk, other) {
+			t.Fatal("CheckKeypair accepted a mismatched verifying key")
+		}
+	}
+	for _, ln := range []int{0, 31, 33, 64} {
+		if _, err := DeriveVerifyingKey(make([]byte, ln)); err != ErrInvalidKeyLength {
+			t.Fatalf("%d byte key: expected ErrInvalidKeyLength, got %v", ln, err)
+		}
+	}
+}
+
+func TestWriteReadSigned(t *testing.T) {
+	sk, vk := TestKeypair("frames")
+	buf := &bytes.Buffer{}
+	msgs := []string{"first", "second message", "third"}
+	for _, m := range msgs {
+		n, err := WriteSigned(buf, sk, vk, []byte(m))
+		if err != nil || n != 4+len(m)+64 {
+			t.Fatalf("write failed: n=%d err=%v", n, err)
+		}
+	}
+	for _, m := range msgs {
+		blob, ok, err := ReadSigned(buf, vk)
+		if err != nil || !ok || string(blob) != m {
+			t.Fatalf("read %q: got %q ok=%v err=%v", m, blob, ok, err)
+		}
+	}
+	if _, _, err := ReadSigned(buf, vk); err != io.EOF {
+		t.Fatalf("expected EOF after last frame, got %v", err)
+	}
+}
+
+//TestSignatureSizes signs messages of every length up to a few blocks
+//and around SHA-512 block (128 byte) boundaries further out, checking
+//that exactly 64 bytes are written and that the signature matches
+//crypto/ed25519, so a bug in the C hash path would show up here
+func TestSignatureSizes(t *testing.T) {
+	sk, vk := TestKeypair("sizes")
+	std := ed25519.NewKeyFromSeed(sk)
+	var sizes []int
+	for i := 0; i <= 3*128; i++ {
+		sizes = append(sizes, i)
+	}
+	for blk := 4; blk <= 64; blk *= 2 {
+		sizes = append(sizes, blk*128-1, blk*128, blk*128+1)
+	}
+	for _, sz := range sizes {
+		msg := make([]byte, sz)
+		rand.Read(msg)
+		buf := bytes.Repeat([]byte{0xA5}, 64+16)
+		SignBlob(sk, vk, buf[:64], msg)
+		if !bytes.Equal(buf[64:], bytes.Repeat([]byte{0xA5}, 16)) {
+			t.Fatalf("size %d: signing wrote past 64 bytes", sz)
+		}
+		sig := buf[:64]
+		if !VerifyBlob(vk, sig, msg) {
+			t.Fatalf("size %d: signature did not verify", sz)
+		}
+		if !bytes.Equal(sig, ed25519.Sign(std, msg)) {
+			t.Fatalf("size %d: signature differs from crypto/ed25519", sz)
+		}
+		if dec, err := UnFmtSig(FmtSig(sig)); err != nil || len(dec) != 64 {
+			t.Fatalf("size %d: formatted signature is not 64 bytes", sz)
+		}
+	}
+}
+
+func TestSignBadLengths(t *testing.T) {
+	sk, vk := TestKeypair("lengths")
+	sig := make([]byte, 64)
+	cases := []struct {
+		sk, vk, into []byte
+		err          error
+	}{
+		{sk, vk, nil, ErrInvalidSigLength},
+		{sk, vk, make([]byte, 63), ErrInvalidSigLength},
+		{sk, vk, make([]byte, 65), ErrInvalidSigLength},
+		{nil, vk, sig, ErrInvalidKeyLength},
+		{sk[:31], vk, sig, ErrInvalidKeyLength},
+		{sk, nil, sig, ErrInvalidKeyLength},
+		{sk, append(vk, 0), sig, ErrInvalidKeyLength},
+	}
+	for i, c := range cases {
+		if err := SignBlob(c.sk, c.vk, c.into, []byte("msg")); err != c.err {
+			t.Fatalf("case %d: SignBlob returned %v, expected %v", i, err, c.err)
+		}
+		if err := SignVector(c.sk, c.vk, c.into, []byte("m"), []byte("sg")); err != c.err {
+			t.Fatalf("case %d: SignVector returned %v, expected %v", i, err, c.err)
+		}
+	}
+	if err := SignVector(sk, vk, sig); err != nil || !VerifyBlob(vk, sig, nil) {
+		t.Fatalf("SignVector with no fields failed: %v", err)
+	}
+	if !VerifyVector(vk, sig) {
+		t.Fatal("VerifyVector with no fields failed")
+	}
+	if VerifyBlob(vk, sig[:63], nil) || VerifyBlob(vk[:31], sig, nil) || VerifyVector(nil, sig) {
+		t.Fatal("verified with a short key or signature")
+	}
+}
+
+func TestSignBlobContext(t *testing.T) {
+	sk, vk := TestKeypair("context")
+	sig, err := SignBlobContext(context.Background(), sk, vk, []byte("hello"))
+	if err != nil || !VerifyBlob(vk, sig, []byte("hello")) {
+		t.Fatalf("signing with a live context failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := SignBlobContext(ctx, sk, vk, []byte("hello")); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestSignBlobWithContext(t *testing.T) {
+	sk, vk := TestKeypair("domains")
+	blob := []byte("routing table update")
+	sig := make([]byte, 64)
+	if err := SignBlobWithContext(sk, vk, sig, blob, "A"); err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyBlobWithContext(vk, sig, blob, "A") {
+		t.Fatal("signature did not verify under its own context")
+	}
+	for _, other := range []string{"B", "", "A\x00", "a"} {
+		if VerifyBlobWithContext(vk, sig, blob, other) {
+			t.Fatalf("context A signature verified under %q", other)
+		}
+	}
+
+	if err := SignBlobWithContext(sk, vk, sig, blob, ""); err != ErrEmptyContext {
+		t.Fatalf("expected ErrEmptyContext, got %v", err)
+	}
+	plain := make([]byte, 64)
+	SignBlob(sk, vk, plain, blob)
+	if VerifyBlobWithContext(vk, plain, blob, "") {
+		t.Fatal("SignBlob signature verified under the empty context")
+	}
+}
+
+func TestVerifyBlobMaxLen(t *testing.T) {
+	sk, vk := TestKeypair("maxlen")
+	blob := []byte("sixteen byte msg")
+	sig := make([]byte, 64)
+	SignBlob(sk, vk, sig, blob)
+	if ok, err := VerifyBlobMaxLen(vk, sig, blob, len(blob)); err != nil || !ok {
+		t.Fatalf("blob of exactly maxLen rejected: ok=%v err=%v", ok, err)
+	}
+	if ok, err := VerifyBlobMaxLen(vk, sig, blob, len(blob)-1); err != ErrMessageTooLarge || ok {
+		t.Fatalf("blob of maxLen+1: ok=%v err=%v", ok, err)
+	}
+	if ok, err := VerifyBlobMaxLen(vk, sig, blob, -1); err != ErrMessageTooLarge || ok {
+		t.Fatalf("negative maxLen: ok=%v err=%v", ok, err)
+	}
+	empty := make([]byte, 64)
+	SignBlob(sk, vk, empty, nil)
+	if ok, err := VerifyBlobMaxLen(vk, empty, nil, -1); err != ErrMessageTooLarge || ok {
+		t.Fatalf("empty blob under negative maxLen: ok=%v err=%v", ok, err)
+	}
+	if ok, err := VerifyBlobMaxLen(vk, sig, []byte("sixteen byte msh"), len(blob)); err != nil || ok {
+		t.Fatalf("wrong blob within maxLen: ok=%v err=%v", ok, err)
+	}
+}
+
+func TestSignAudited(t *testing.T) {
+	sk, vk := TestKeypair("audited")
+	blob := []byte("release 1.2.3")
+	sig, fp, err := SignAudited(sk, vk, blob)
+	if err != nil || !VerifyBlob(vk, sig, blob) {
+		t.Fatalf("audited signature did not verify: %v", err)
+	}
+	if fp != Fingerprint(vk) {
+		t.Fatalf("fingerprint %s, expected %s", fp, Fingerprint(vk))
+	}
+	if sig, fp, err := SignAudited(sk, vk[:31], blob); err != ErrInvalidKeyLength || sig != nil || fp != "" {
+		t.Fatalf("bad key: sig=%x fp=%q err=%v", sig, fp, err)
+	}
+}
+
+func TestHashStream(t *testing.T) {
+	data := make([]byte, 3*65536+17)
+	rand.Read(data)
+	want := HashBlob(data)
+
+	bytewise, err := HashStream(iotest.OneByteReader(bytes.NewReader(data)))
+	if err != nil || !bytes.Equal(bytewise, want) {
+		t.Fatalf("1 byte chunks gave a different digest: %v", err)
+	}
+	h := NewHasher()
+	for off := 0; off < len(data); off += 65536 {
+		h.Write(data[off:min(off+65536, len(data))])
+	}
+	if !bytes.Equal(h.Sum(nil), want) {
+		t.Fatal("64KB chunks gave a different digest")
+	}
+
+	rv, err := UnFmtDigest(FmtHash(want))
+	if err != nil || !bytes.Equal(rv, want) {
+		t.Fatalf("64 byte digest did not round trip: %v", err)
+	}
+	if _, err := UnFmtDigest(FmtHash(want[:32])); err == nil {
+		t.Fatal("32 byte hash decoded as a digest")
+	}
+	rv, err = UnFmtHash(FmtHash(want[:32]))
+	if err != nil || !bytes.Equal(rv, want[:32]) {
+		t.Fatalf("32 byte hash did not round trip: %v", err)
+	}
+	if _, err := UnFmtHash(FmtHash(want)); err == nil {
+		t.Fatal("64 byte digest decoded as a 32 byte hash")
+	}
+}
+
+func TestHMACSHA512(t *testing.T) {
+	//RFC 4231 test case 2
+	mac := HMACSHA512([]byte("Jefe"), []byte("what do ya want for nothing?"))
+	want := "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737"
+	if hex.EncodeToString(mac) != want {
+		t.Fatalf("got %x", mac)
+	}
+}
+
+func TestCommitKey(t *testing.T) {
+	_, vk := TestKeypair("committed")
+	_, other := TestKeypair("other")
+	c, salt := CommitKey(vk)
+	if !OpenCommitment(c, salt, vk) {
+		t.Fatal("commitment did not open")
+	}
+	if OpenCommitment(c, salt, other) {
+		t.Fatal("commitment opened for a different key")
+	}
+	c2, _ := CommitKey(vk)
+	if bytes.Equal(c, c2) {
+		t.Fatal("two commitments to the same key are equal")
+	}
+}
+
+func TestSignTextNormalization(t *testing.T) {
+	sk, vk := TestKeypair("text")
+	composed := "café"
+	decomposed := "café"
+	sig := SignText(sk, vk, decomposed)
+	if !VerifyText(vk, sig, composed) {
+		t.Fatal("composed text did not verify against decomposed signature")
+	}
+	if VerifyText(vk, sig, "cafe") {
+		t.Fatal("different text verified")
+	}
+}
+
+func TestSigningKeyThreshold(t *testing.T) {
+	sk, vk := TestKeypair("counted")
+	k := NewSigningKey(sk, vk)
+	fired := 0
+	k.OnThreshold(3, func() { fired++ })
+	for i := 0; i < 3; i++ {
+		if !VerifyBlob(vk, k.Sign([]byte("msg")), []byte("msg")) {
+			t.Fatal("signature did not verify")
+		}
+	}
+	if fired != 0 {
+		t.Fatal("threshold fired before being exceeded")
+	}
+	k.Sign([]byte("msg"))
+	k.Sign([]byte("msg"))
+	if fired != 1 || k.UseCount() != 5 {
+		t.Fatalf("fired=%d uses=%d", fired, k.UseCount())
+	}
+}
+
+//eofReaderAt returns io.EOF with any read that reaches the end of r, as
+//io.ReaderAt allows
+type eofReaderAt struct {
+	r *bytes.Reader
+}
+
+func (e eofReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := e.r.ReadAt(p, off)
+	if err == nil && off+int64(n) == e.r.Size() {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func TestVerifyRecordFile(t *testing.T) {
+	sk, vk := TestKeypair("records")
+	file := &bytes.Buffer{}
+	var specs []RecordSpec
+	for _, m := range []string{"alpha", "", "gamma record"} {
+		sig := make([]byte, 64)
+		SignBlob(sk, vk, sig, []byte(m))
+		spec := RecordSpec{Offset: int64(file.Len()), MsgLen: len(m)}
+		file.WriteString(m)
+		spec.SigOffset = int64(file.Len())
+		file.Write(sig)
+		specs = append(specs, spec)
+	}
+	data := file.Bytes()
+	data[specs[2].Offset] ^= 0xff
+	res, err := VerifyRecordFile(bytes.NewReader(data), specs, [][]byte{vk})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res[0] || !res[1] || res[2] {
+		t.Fatalf("unexpected results %v", res)
+	}
+	//The last signature ends the file, so this reader returns io.EOF
+	//alongside it
+	res, err = VerifyRecordFile(eofReaderAt{bytes.NewReader(data)}, specs, [][]byte{vk})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res[0] || !res[1] || res[2] {
+		t.Fatalf("unexpected results %v", res)
+	}
+	specs[0].SigOffset = int64(len(data))
+	if _, err := VerifyRecordFile(bytes.NewReader(data), specs, [][]byte{vk}); err == nil {
+		t.Fatal("expected error reading past end of file")
+	}
+}
+
+func TestLoadSignedConfig(t *testing.T) {
+	sk, vk := TestKeypair("config")
+	_, other := TestKeypair("other")
+	path := filepath.Join(t.TempDir(), "app.conf")
+	body := []byte("listen = :8080\nlevel = debug\n")
+	if err := os.WriteFile(path, SignConfig(sk, vk, body), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, signer, err := LoadSignedConfig(path, [][]byte{other, vk})
+	if err != nil || !bytes.Equal(got, body) || !bytes.Equal(signer, vk) {
+		t.Fatalf("load failed: %v", err)
+	}
+	if _, _, err := LoadSignedConfig(path, [][]byte{other}); err == nil {
+		t.Fatal("config accepted without a trusted signer")
+	}
+}
+
+func TestDeterministicSign(t *testing.T) {
+	vk1, sig1 := DeterministicSign([]byte("golden"), []byte("message"))
+	vk2, sig2 := DeterministicSign([]byte("golden"), []byte("message"))
+	if !bytes.Equal(vk1, vk2) || !bytes.Equal(sig1, sig2) {
+		t.Fatal("DeterministicSign is not deterministic")
+	}
+	if !VerifyBlob(vk1, sig1, []byte("message")) {
+		t.Fatal("signature did not verify")
+	}
+	seed := bytes.Repeat([]byte{7}, 32)
+	sk, vk, _ := GenerateKeypairFromSeed(seed)
+	want := make([]byte, 64)
+	SignBlob(sk, vk, want, []byte("message"))
+	if vk3, sig3 := DeterministicSign(seed, []byte("message")); !bytes.Equal(vk3, vk) || !bytes.Equal(sig3, want) {
+		t.Fatal("32 byte seed does not match GenerateKeypairFromSeed")
+	}
+}
+
+func TestFileWithLength(t *testing.T) {
+	sk, vk := TestKeypair("files")
+	data := make([]byte, 100000)
+	rand.Read(data)
+	sig, err := SignFileWithLength(sk, vk, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := VerifyFileWithLength(vk, sig, bytes.NewReader(data)); !ok || err != nil {
+		t.Fatalf("full file did not verify: %v", err)
+	}
+	if ok, _ := VerifyFileWithLength(vk, sig, bytes.NewReader(data[:len(data)-1])); ok {
+		t.Fatal("truncated file verified")
+	}
+}
+
+func TestFmtSigs(t *testing.T) {
+	sk, vk := TestKeypair("sigs")
+	sigs := make([][]byte, 3)
+	for i := range sigs {
+		sigs[i] = make([]byte, 64)
+		SignBlob(sk, vk, sigs[i], []byte{byte(i)})
+	}
+	strs := FmtSigs(sigs)
+	rv, err := UnFmtSigs(strs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range sigs {
+		if !bytes.Equal(rv[i], sigs[i]) {
+			t.Fatalf("signature %d did not round trip", i)
+		}
+	}
+	strs[1] = strs[1][:40]
+	if _, err := UnFmtSigs(strs); err == nil || !strings.Contains(err.Error(), "Signature 1") {
+		t.Fatalf("expected error naming signature 1, got %v", err)
+	}
+}
+
+func TestVerifyHeaderBody(t *testing.T) {
+	sk, vk := TestKeypair("header body")
+	header := []byte("HDR\x01\x02")
+	body := make([]byte, 200000)
+	rand.Read(body)
+	sig := make([]byte, 64)
+	SignVector(sk, vk, sig, header, body)
+	if ok, err := VerifyHeaderBody(vk, sig, header, bytes.NewReader(body)); !ok || err != nil {
+		t.Fatalf("header and body did not verify: %v", err)
+	}
+	SignBlob(sk, vk, sig, append(append([]byte{}, header...), body...))
+	if ok, _ := VerifyHeaderBody(vk, sig, header, bytes.NewReader(body)); !ok {
+		t.Fatal("concatenated signature did not verify")
+	}
+	body[100] ^= 1
+	if ok, _ := VerifyHeaderBody(vk, sig, header, bytes.NewReader(body)); ok {
+		t.Fatal("tampered body verified")
+	}
+}
+
+func TestValidateKeyDir(t *testing.T) {
+	dir := t.TempDir()
+	sk, vk := TestKeypair("dir good")
+	_, other := TestKeypair("dir bad")
+	os.WriteFile(filepath.Join(dir, "good.key"), []byte(FmtKey(sk)+"\n"+FmtKey(vk)+"\n"), 0600)
+	os.WriteFile(filepath.Join(dir, "mismatch.key"), []byte(FmtKey(sk)+" "+FmtKey(other)), 0600)
+	os.WriteFile(filepath.Join(dir, "garbage.key"), []byte("not a key"), 0600)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0600)
+	res, err := ValidateKeyDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(res))
+	}
+	byName := make(map[string]KeyFileResult)
+	for _, r := range res {
+		byName[filepath.Base(r.Path)] = r
+	}
+	if r := byName["good.key"]; r.Err != nil || r.Fingerprint != Fingerprint(vk) {
+		t.Fatalf("good key: %+v", r)
+	}
+	if r := byName["mismatch.key"]; r.Err == nil || r.Fingerprint != Fingerprint(other) {
+		t.Fatalf("mismatched key: %+v", r)
+	}
+	if byName["garbage.key"].Err == nil {
+		t.Fatal("garbage key file validated")
+	}
+}
+
+func TestMessageKeys(t *testing.T) {
+	master, masterVk := TestKeypair("master")
+	sk1, vk1, _ := DeriveMessageKey(master, []byte("msg-1"))
+	sk1b, _, _ := DeriveMessageKey(master, []byte("msg-1"))
+	_, vk2, _ := DeriveMessageKey(master, []byte("msg-2"))
+	if !bytes.Equal(sk1, sk1b) || bytes.Equal(vk1, vk2) || !CheckKeypair(sk1, vk1) {
+		t.Fatal("message keys are not deterministic and distinct")
+	}
+	msgVk, proof, sig := SignWithMessageKey(master, masterVk, []byte("msg-1"), []byte("payload"))
+	if !bytes.Equal(msgVk, vk1) {
+		t.Fatal("signing used a different derived key")
+	}
+	if !VerifyWithMessageKey(masterVk, []byte("msg-1"), msgVk, proof, sig, []byte("payload")) {
+		t.Fatal("message key signature did not verify")
+	}
+	if VerifyWithMessageKey(masterVk, []byte("msg-2"), msgVk, proof, sig, []byte("payload")) {
+		t.Fatal("endorsement verified for the wrong message id")
+	}
+}
+
+func TestVerifyBlobSafe(t *testing.T) {
+	sk, vk := TestKeypair("leaky")
+	sig := make([]byte, 64)
+	SignBlob(sk, vk, sig, []byte("msg"))
+	if ok, err := VerifyBlobSafe(vk, sig, []byte("msg")); !ok || err != nil {
+		t.Fatalf("unflagged key rejected: %v", err)
+	}
+	MarkCompromised(vk)
+	if !RejectCompromised(vk) {
+		t.Fatal("key not flagged")
+	}
+	if ok, err := VerifyBlobSafe(vk, sig, []byte("msg")); ok || err != ErrCompromisedKey {
+		t.Fatalf("flagged key accepted: %v", err)
+	}
+}
+
+func TestSignWithExpiry(t *testing.T) {
+	sk, vk := TestKeypair("expiry")
+	signed := SignWithExpiry(sk, vk, []byte("token"), time.Now().Add(time.Hour))
+	if ok, expired := VerifyWithExpiry(vk, signed, []byte("token")); !ok || expired {
+		t.Fatal("fresh token rejected")
+	}
+	old := SignWithExpiry(sk, vk, []byte("token"), time.Now().Add(-time.Hour))
+	if ok, expired := VerifyWithExpiry(vk, old, []byte("token")); ok || !expired {
+		t.Fatal("expired token accepted")
+	}
+	//Moving the deadline invalidates the signature
+	copy(old[:8], signed[:8])
+	if ok, expired := VerifyWithExpiry(vk, old, []byte("token")); ok || expired {
+		t.Fatal("token with forged expiry accepted")
+	}
+}
+
+func TestRekeyExport(t *testing.T) {
+	sk, vk := TestKeypair("exported")
+	blob, err := ExportKey(sk, vk, "old secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reblob, err := RekeyExport(blob, "old secret", "new secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ImportKey(reblob, "old secret"); err == nil {
+		t.Fatal("rekeyed export opened with the old passphrase")
+	}
+	isk, ivk, err := ImportKey(reblob, "new secret")
+	if err != nil || !bytes.Equal(isk, sk) || !bytes.Equal(ivk, vk) {
+		t.Fatalf("rekeyed export did not import: %v", err)
+	}
+	if _, err := RekeyExport(blob, "wrong", "new secret"); err == nil {
+		t.Fatal("rekeyed with the wrong passphrase")
+	}
+
+	//Counts past the ceiling must be refused without deriving a key
+	costly := append([]byte{}, blob...)
+	binary.BigEndian.PutUint32(costly[1:5], 0xFFFFFFFF)
+	start := time.Now()
+	if _, _, err := ImportKey(costly, "old secret"); err == nil {
+		t.Fatal("imported an export with 2^32-1 iterations")
+	}
+	if _, err := RekeyExport(costly, "old secret", "new secret"); err == nil {
+		t.Fatal("rekeyed an export with 2^32-1 iterations")
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("iteration count was not checked before key derivation")
+	}
+}
+
+func TestSigningWriter(t *testing.T) {
+	sk, vk := TestKeypair("writer")
+	out := &bytes.Buffer{}
+	sw := NewSigningWriter(out, sk, vk)
+	data := make([]byte, 70000)
+	rand.Read(data)
+	for i := 0; i < len(data); i += 1000 {
+		end := i + 1000
+		if end > len(data) {
+			end = len(data)
+		}
+		sw.Write(data[i:end])
+	}
+	sig, err := sw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Fatal("data was not passed through unchanged")
+	}
+	fsig, _ := SignFileWithLength(sk, vk, bytes.NewReader(data))
+	if !bytes.Equal(sig, fsig) {
+		t.Fatal("writer signature differs from SignFileWithLength")
+	}
+	if ok, _ := VerifyFileWithLength(vk, sig, bytes.NewReader(data)); !ok {
+		t.Fatal("writer signature did not verify")
+	}
+	if _, err := sw.Write([]byte("late")); err == nil {
+		t.Fatal("write after close succeeded")
+	}
+}
+
+func TestSignMap(t *testing.T) {
+	sk, vk := TestKeypair("maps")
+	m := map[string][]byte{"b": []byte("2"), "a": []byte("1"), "c": nil}
+	sig := SignMap(sk, vk, m)
+	for i := 0; i < 10; i++ {
+		if !bytes.Equal(sig, SignMap(sk, vk, m)) {
+			t.Fatal("map signature depends on iteration order")
+		}
+	}
+	copied := map[string][]byte{"c": {}, "a": []byte("1"), "b": []byte("2")}
+	if !VerifyMap(vk, sig, copied) {
+		t.Fatal("equal map did not verify")
+	}
+	//Moving bytes between key and value must change the encoding
+	if VerifyMap(vk, sig, map[string][]byte{"b": []byte("2"), "a1": nil, "c": nil}) {
+		t.Fatal("reframed map verified")
+	}
+}
+
+func TestVerifyBatchStats(t *testing.T) {
+	var vks, sigs, blobs [][]byte
+	for i := 0; i < 8; i++ {
+		sk, vk := TestKeypair(fmt.Sprintf("batch %d", i))
+		blob := []byte(fmt.Sprintf("message %d", i))
+		sig := make([]byte, 64)
+		SignBlob(sk, vk, sig, blob)
+		vks, sigs, blobs = append(vks, vk), append(sigs, sig), append(blobs, blob)
+	}
+	blobs[3] = []byte("tampered")
+	res, stats, err := VerifyBatchStats(vks, sigs, blobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ok := range res {
+		if ok != (i != 3) {
+			t.Fatalf("result %d is %v", i, ok)
+		}
+	}
+	if stats.Count != 8 || stats.Total <= 0 || stats.PerSignature != stats.Total/8 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	//A non canonical S must be reported invalid, as VerifyBlob does
+	sigs[5] = withSPlusL(sigs[5], 2)
+	res, stats, err = VerifyBatchStats(vks, sigs, blobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ok := range res {
+		if ok != (i != 3 && i != 5) || ok != VerifyBlob(vks[i], sigs[i], blobs[i]) {
+			t.Fatalf("result %d is %v", i, ok)
+		}
+	}
+	if stats.Count != 8 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if res, _, err := VerifyBatchStats(vks, sigs[1:], blobs); err != ErrBatchLengthMismatch || res != nil {
+		t.Fatalf("expected ErrBatchLengthMismatch, got %v %v", res, err)
+	}
+}
+
+//withSPlusL returns sig with k*L added to its S half, the same scalar as
+//S but not its canonical encoding
+func withSPlusL(sig []byte, k int64) []byte {
+	L, _ := new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+	le := make([]byte, 32)
+	for i := range le {
+		le[i] = sig[63-i]
+	}
+	S := new(big.Int).SetBytes(le)
+	S.Add(S, L.Mul(L, big.NewInt(k)))
+	be := S.FillBytes(make([]byte, 33))
+	if be[0] != 0 {
+		panic("S+kL does not fit in 32 bytes")
+	}
+	rv := append([]byte{}, sig[:32]...)
+	for i := 32; i > 0; i-- {
+		rv = append(rv, be[i])
+	}
+	return rv
+}
+
+//withTorsionR returns sig with a point of order 8 added to R. It fails
+//plain verification but disappears from a batch equation whenever the
+//random coefficient for it is a multiple of 8.
+func withTorsionR(t *testing.T, sig []byte) []byte {
+	enc, _ := hex.DecodeString("c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a")
+	T, err := new(edwards25519.Point).SetBytes(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	R, err := new(edwards25519.Point).SetBytes(sig[:32])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(new(edwards25519.Point).Add(R, T).Bytes(), sig[32:]...)
+}
+
+//signedBatch returns n valid signatures under distinct fixture keys
+func signedBatch(n int) (vks, sigs, blobs [][]byte) {
+	for i := 0; i < n; i++ {
+		sk, vk := TestKeypair(fmt.Sprintf("batch %d", i))
+		blob := []byte(fmt.Sprintf("message %d", i))
+		sig := make([]byte, 64)
+		SignBlob(sk, vk, sig, blob)
+		vks, sigs, blobs = append(vks, vk), append(sigs, sig), append(blobs, blob)
+	}
+	return vks, sigs, blobs
+}
+
+//TestBatchVerifyBlobMalleable checks BatchVerifyBlob against VerifyBlob
+//for signatures a batch equation can accept although single
+//verification does not. The torsion case is repeated because a batch
+//equation only misses it for some random coefficients.
+func TestBatchVerifyBlobMalleable(t *testing.T) {
+	for name, tweak := range map[string]func(sig []byte) []byte{
+		"S+L":  func(sig []byte) []byte { return withSPlusL(sig, 1) },
+		"S+2L": func(sig []byte) []byte { return withSPlusL(sig, 2) },
+		"R+T":  func(sig []byte) []byte { return withTorsionR(t, sig) },
+	} {
+		for _, n := range []int{4, 8, 70} {
+			vks, sigs, blobs := signedBatch(n)
+			sigs[n/2] = tweak(sigs[n/2])
+			for run := 0; run < 50; run++ {
+				res, err := BatchVerifyBlob(vks, sigs, blobs)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for i, ok := range res {
+					if ok != VerifyBlob(vks[i], sigs[i], blobs[i]) {
+						t.Fatalf("%s, batch of %d: result %d disagrees with VerifyBlob", name, n, i)
+					}
+					if ok != (i != n/2) {
+						t.Fatalf("%s, batch of %d: result %d is %v", name, n, i, ok)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestBatchVerifyBlob(t *testing.T) {
+	//Enough items to span several donna batches of 64
+	const NN = 150
+	var vks, sigs, blobs [][]byte
+	for i := 0; i < NN; i++ {
+		sk, vk := TestKeypair(fmt.Sprintf("batch %d", i))
+		blob := []byte(fmt.Sprintf("message %d", i))
+		if i%7 == 0 {
+			blob = nil
+		}
+		sig := make([]byte, 64)
+		SignBlob(sk, vk, sig, blob)
+		vks, sigs, blobs = append(vks, vk), append(sigs, sig), append(blobs, blob)
+	}
+	bad := map[int]bool{3: true, 64: true, 100: true, 101: true, 149: true}
+	blobs[3] = []byte("tampered")
+	sigs[64] = append([]byte{}, sigs[64]...)
+	sigs[64][10] ^= 0x01
+	vks[100] = vks[0]
+	sigs[101] = sigs[101][:63]
+	vks[149] = nil
+
+	res, err := BatchVerifyBlob(vks, sigs, blobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ok := range res {
+		if ok != !bad[i] {
+			t.Fatalf("result %d is %v", i, ok)
+		}
+		if ok != VerifyBlob(vks[i], sigs[i], blobs[i]) {
+			t.Fatalf("result %d disagrees with VerifyBlob", i)
+		}
+	}
+	if res, err := BatchVerifyBlob(nil, nil, nil); err != nil || len(res) != 0 {
+		t.Fatalf("empty batch: %v %v", res, err)
+	}
+	if _, err := BatchVerifyBlob(vks, sigs, blobs[1:]); err != ErrBatchLengthMismatch {
+		t.Fatalf("expected ErrBatchLengthMismatch, got %v", err)
+	}
+}
+
+func TestMustVerify(t *testing.T) {
+	sk, vk := TestKeypair("must")
+	sig := make([]byte, 64)
+	SignBlob(sk, vk, sig, []byte("internal"))
+	MustVerify(vk, sig, []byte("internal"))
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MustVerify did not panic on a bad signature")
+		}
+	}()
+	MustVerify(vk, sig, []byte("tampered"))
+}
+
+func TestDetectSwappedKeypair(t *testing.T) {
+	sk, vk := TestKeypair("swap")
+	if rsk, rvk, swapped, ok := DetectSwappedKeypair(sk, vk); !ok || swapped || !bytes.Equal(rsk, sk) || !bytes.Equal(rvk, vk) {
+		t.Fatal("correct order not recognised")
+	}
+	if rsk, rvk, swapped, ok := DetectSwappedKeypair(vk, sk); !ok || !swapped || !bytes.Equal(rsk, sk) || !bytes.Equal(rvk, vk) {
+		t.Fatal("swapped order not corrected")
+	}
+	_, other := TestKeypair("unrelated")
+	if _, _, _, ok := DetectSwappedKeypair(sk, other); ok {
+		t.Fatal("unrelated keys accepted")
+	}
+}
+
+func TestSignCAS(t *testing.T) {
+	sk, vk := TestKeypair("cas")
+	data := []byte("content addressed object")
+	cid, sig := SignCAS(sk, vk, data)
+	if len(cid) != 66 || cid[0] != 0x13 || cid[1] != 0x40 {
+		t.Fatalf("unexpected multihash prefix %x", cid[:2])
+	}
+	if !VerifyCAS(vk, cid, sig, data) {
+		t.Fatal("CAS object did not verify")
+	}
+	if VerifyCAS(vk, cid, sig, []byte("other object")) {
+		t.Fatal("CAS verified for different data")
+	}
+	sum := sha256.Sum256(data)
+	if !VerifyCAS(vk, append([]byte{0x12, 0x20}, sum[:]...), sig, data) {
+		t.Fatal("SHA-256 multihash rejected")
+	}
+}
+
+func TestGenerateKeypairFromSeed(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, 32)
+	sk1, vk1, err1 := GenerateKeypairFromSeed(seed)
+	sk2, vk2, err2 := GenerateKeypairFromSeed(seed)
+	if !bytes.Equal(sk1, sk2) || !bytes.Equal(vk1, vk2) || err1 != err2 {
+		t.Fatal("same seed produced different keypairs")
+	}
+	if !CheckKeypair(sk1, vk1) {
+		t.Fatal("seeded keypair does not validate")
+	}
+	seed[0] ^= 0x01
+	_, vk3, _ := GenerateKeypairFromSeed(seed)
+	if bytes.Equal(vk1, vk3) {
+		t.Fatal("different seeds produced the same verifying key")
+	}
+	sawDash := false
+	for i := 0; i < 256; i++ {
+		seed[0] = byte(i)
+		sk, vk, err := GenerateKeypairFromSeed(seed)
+		if (err == ErrDashPrefixedKey) != (FmtKey(vk)[0] == '-') {
+			t.Fatalf("seed %d: dash flag %v does not match key %s", i, err, FmtKey(vk))
+		}
+		if err == ErrDashPrefixedKey {
+			sawDash = true
+			if !CheckKeypair(sk, vk) {
+				t.Fatal("dash prefixed keypair was not returned intact")
+			}
+		}
+	}
+	if !sawDash {
+		t.Fatal("no dash prefixed key in 256 seeds")
+	}
+	if _, _, err := GenerateKeypairFromSeed(seed[:31]); err != ErrInvalidKeyLength {
+		t.Fatalf("expected ErrInvalidKeyLength, got %v", err)
+	}
+}
+
+func TestWipe(t *testing.T) {
+	sk := make([]byte, 32)
+	vk := make([]byte, 32)
+	if err := GenerateKeypairInto(sk, vk); err != nil {
+		t.Fatal(err)
+	}
+	if !CheckKeypair(sk, vk) || FmtKey(vk)[0] == '-' {
+		t.Fatal("generated keypair does not validate")
+	}
+	Wipe(sk)
+	if !bytes.Equal(sk, make([]byte, 32)) {
+		t.Fatalf("buffer not zero after Wipe: %x", sk)
+	}
+	if err := GenerateKeypairInto(sk[:31], vk); err != ErrInvalidKeyLength {
+		t.Fatalf("expected ErrInvalidKeyLength, got %v", err)
+	}
+}
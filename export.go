@@ -0,0 +1,127 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+)
+
+//An exported key is
+//  version (1) || PBKDF2 iterations (uint32 BE) || salt (16) || nonce (12)
+//  || AES-256-GCM(sk || vk)
+//with the key derived from the passphrase by PBKDF2-SHA-512 and the
+//header used as additional data. The iteration count is read from an
+//untrusted blob, so counts above exportMaxIterations are refused before
+//any key derivation, or a crafted export could pin a CPU for hours.
+const (
+	exportVersion       = 1
+	exportIterations    = 210000
+	exportMaxIterations = 10 * exportIterations
+	exportHeaderLen     = 1 + 4 + 16 + 12
+	exportLen           = exportHeaderLen + 64 + 16
+)
+
+func exportAEAD(pass string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha512.New, pass, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	defer Wipe(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+//sealExport encrypts the 64 byte sk || vk plaintext under pass
+func sealExport(plain []byte, pass string) ([]byte, error) {
+	hdr := make([]byte, exportHeaderLen, exportLen)
+	hdr[0] = exportVersion
+	binary.BigEndian.PutUint32(hdr[1:5], exportIterations)
+	if _, err := rand.Read(hdr[5:]); err != nil {
+		return nil, err
+	}
+	aead, err := exportAEAD(pass, hdr[5:21], exportIterations)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(hdr, hdr[21:], plain, hdr), nil
+}
+
+//openExport returns the sk || vk plaintext of an exported key. The
+//caller should Wipe it when done.
+func openExport(blob []byte, pass string) ([]byte, error) {
+	if len(blob) != exportLen || blob[0] != exportVersion {
+		return nil, errors.New("Invalid export")
+	}
+	iterations := int(binary.BigEndian.Uint32(blob[1:5]))
+	if iterations < 1 || iterations > exportMaxIterations {
+		return nil, errors.New("Invalid export iteration count")
+	}
+	aead, err := exportAEAD(pass, blob[5:21], iterations)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, blob[21:exportHeaderLen], blob[exportHeaderLen:], blob[:exportHeaderLen])
+	if err != nil {
+		return nil, errors.New("Wrong passphrase or corrupt export")
+	}
+	return plain, nil
+}
+
+//ExportKey encrypts a keypair under a passphrase for storage
+func ExportKey(sk []byte, vk []byte, pass string) ([]byte, error) {
+	if len(sk) != 32 || len(vk) != 32 {
+		return nil, errors.New("Invalid length")
+	}
+	plain := make([]byte, 64)
+	defer Wipe(plain)
+	copy(plain, sk)
+	copy(plain[32:], vk)
+	return sealExport(plain, pass)
+}
+
+//ImportKey decrypts a keypair written by ExportKey
+func ImportKey(blob []byte, pass string) (sk []byte, vk []byte, err error) {
+	plain, err := openExport(blob, pass)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer Wipe(plain)
+	sk = append([]byte{}, plain[:32]...)
+	vk = append([]byte{}, plain[32:]...)
+	return sk, vk, nil
+}
+
+//RekeyExport re-encrypts an exported key under newPass. The decrypted
+//key is only held in one buffer, which is zeroed before returning.
+func RekeyExport(blob []byte, oldPass string, newPass string) ([]byte, error) {
+	plain, err := openExport(blob, oldPass)
+	if err != nil {
+		return nil, err
+	}
+	defer Wipe(plain)
+	return sealExport(plain, newPass)
+}
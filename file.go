@@ -0,0 +1,136 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+)
+
+var fileLengthTag = []byte("bw2crypto file with length\x00")
+
+//fileLengthBlob streams r and returns
+//  tag || uint64 BE total length || H(content)
+//which is what SignFileWithLength signs
+func fileLengthBlob(r io.Reader) ([]byte, error) {
+	h := sha512.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return nil, err
+	}
+	return lengthBlob(uint64(n), h), nil
+}
+
+func lengthBlob(n uint64, h hash.Hash) []byte {
+	blob := make([]byte, len(fileLengthTag), len(fileLengthTag)+8+64)
+	copy(blob, fileLengthTag)
+	blob = binary.BigEndian.AppendUint64(blob, n)
+	return h.Sum(blob)
+}
+
+//SignFileWithLength signs the content of r bound to its total length,
+//so a truncated copy of the file does not verify
+func SignFileWithLength(sk []byte, vk []byte, r io.Reader) ([]byte, error) {
+	blob, err := fileLengthBlob(r)
+	if err != nil {
+		return nil, err
+	}
+	sig := make([]byte, 64)
+	if err := SignBlob(sk, vk, sig, blob); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+//VerifyFileWithLength checks a signature from SignFileWithLength over
+//the whole of r. An error is only returned if r cannot be read.
+func VerifyFileWithLength(vk []byte, sig []byte, r io.Reader) (bool, error) {
+	blob, err := fileLengthBlob(r)
+	if err != nil {
+		return false, err
+	}
+	if len(sig) != 64 {
+		return false, nil
+	}
+	return VerifyBlob(vk, sig, blob), nil
+}
+
+//VerifyHeaderBody checks a signature made over header || body, for
+//example by SignVector(sk, vk, sig, header, body), streaming body into
+//the hash instead of buffering it. An error is only returned if body
+//cannot be read.
+func VerifyHeaderBody(vk []byte, sig []byte, header []byte, body io.Reader) (bool, error) {
+	if len(vk) != 32 || len(sig) != 64 {
+		return false, nil
+	}
+	//Ed25519 verification only needs H(R,A,m), so unlike signing it
+	//can be done in a single pass over m
+	h := sha512.New()
+	h.Write(sig[:32])
+	h.Write(vk)
+	h.Write(header)
+	if _, err := io.Copy(h, body); err != nil {
+		return false, err
+	}
+	return verifyHram(vk, sig, h.Sum(nil)), nil
+}
+
+//SigningWriter passes writes through to an underlying writer while
+//hashing them. Close returns the same signature SignFileWithLength
+//would make over everything written, so it can be checked with
+//VerifyFileWithLength.
+type SigningWriter struct {
+	w      io.Writer
+	sk     []byte
+	vk     []byte
+	h      hash.Hash
+	n      uint64
+	closed bool
+}
+
+func NewSigningWriter(w io.Writer, sk []byte, vk []byte) *SigningWriter {
+	return &SigningWriter{w: w, sk: sk, vk: vk, h: sha512.New()}
+}
+
+//Write writes p to the underlying writer, hashing the bytes it accepted
+func (s *SigningWriter) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, errors.New("Write after Close")
+	}
+	n, err := s.w.Write(p)
+	s.h.Write(p[:n])
+	s.n += uint64(n)
+	return n, err
+}
+
+//Close returns the signature over all bytes written. It does not close
+//the underlying writer.
+func (s *SigningWriter) Close() (sig []byte, err error) {
+	if s.closed {
+		return nil, errors.New("Already closed")
+	}
+	s.closed = true
+	sig = make([]byte, 64)
+	if err := SignBlob(s.sk, s.vk, sig, lengthBlob(s.n, s.h)); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
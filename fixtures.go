@@ -0,0 +1,59 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import "crypto/sha512"
+
+//TestKeypair returns a keypair derived from the SHA-512 hash of label.
+//The same label always yields the same keys, which is handy for test
+//fixtures. Anyone who knows the label knows the signing key, so this
+//must NOT be used for production keys. Both keys are nil without a
+//signing backend.
+func TestKeypair(label string) (sk []byte, vk []byte) {
+	if Supported() != nil {
+		return nil, nil
+	}
+	seed := sha512.Sum512([]byte(label))
+	sk = make([]byte, 32)
+	copy(sk, seed[:32])
+	vk = publicKey(sk)
+	return
+}
+
+//DeterministicSign derives a keypair from seed and signs msg with it,
+//returning the verifying key and signature. A 32 byte seed gives the
+//keypair of GenerateKeypairFromSeed, any other length is treated as a
+//label and gives the TestKeypair for it. The output only depends on
+//seed and msg, so it is suitable for golden file tests, and like
+//TestKeypair it must NOT be used with production keys. Both are nil
+//without a signing backend.
+func DeterministicSign(seed []byte, msg []byte) (vk []byte, sig []byte) {
+	var sk []byte
+	if len(seed) == 32 {
+		//A dash prefixed key is still a valid key
+		sk, vk, _ = GenerateKeypairFromSeed(seed)
+	} else {
+		sk, vk = TestKeypair(string(seed))
+	}
+	if sk == nil {
+		return nil, nil
+	}
+	sig = make([]byte, 64)
+	SignBlob(sk, vk, sig, msg)
+	return vk, sig
+}
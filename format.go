@@ -0,0 +1,200 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//DeriveVerifyingKey recomputes the verifying key for a 32 byte signing
+//key, so only sk needs to be stored
+func DeriveVerifyingKey(sk []byte) ([]byte, error) {
+	if err := Supported(); err != nil {
+		return nil, err
+	}
+	if len(sk) != 32 {
+		return nil, ErrInvalidKeyLength
+	}
+	return publicKey(sk), nil
+}
+
+//CheckKeypair returns true if vk is the verifying key for sk
+func CheckKeypair(sk []byte, vk []byte) bool {
+	derived, err := DeriveVerifyingKey(sk)
+	return err == nil && bytes.Equal(derived, vk)
+}
+
+//DetectSwappedKeypair works out which of a and b is the signing key by
+//deriving the verifying key of each. ok is false if neither is the
+//signing key for the other, or if there is no signing backend, swapped
+//is true if b was the signing key.
+func DetectSwappedKeypair(a []byte, b []byte) (sk []byte, vk []byte, swapped bool, ok bool) {
+	if Supported() != nil || len(a) != 32 || len(b) != 32 {
+		return nil, nil, false, false
+	}
+	if bytes.Equal(publicKey(a), b) {
+		return a, b, false, true
+	}
+	if bytes.Equal(publicKey(b), a) {
+		return b, a, true, true
+	}
+	return nil, nil, false, false
+}
+
+func FmtKey(key []byte) string {
+	return base64.URLEncoding.EncodeToString(key)
+}
+
+//UnFmtKey decodes a key formatted by FmtKey. Only the exact output of
+//FmtKey is accepted, so each key has a single string form: anything
+//that isn't canonical padded base64, including line breaks and non zero
+//padding bits, gives a base64.CorruptInputError, and anything that
+//doesn't decode to 32 bytes gives ErrInvalidKeyLength.
+func UnFmtKey(key string) ([]byte, error) {
+	//The decoder skips line breaks even in strict mode
+	if i := strings.IndexAny(key, "\r\n"); i >= 0 {
+		return nil, base64.CorruptInputError(i)
+	}
+	rv, err := base64.URLEncoding.Strict().DecodeString(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(rv) != 32 {
+		return nil, ErrInvalidKeyLength
+	}
+	return rv, nil
+}
+
+//ValidateKey checks that s is a key UnFmtKey accepts and reports the
+//same error if not. Keys that format with a leading '-', which
+//GenerateKeypair never produces, give ErrDashPrefixedKey.
+func ValidateKey(s string) error {
+	if _, err := UnFmtKey(s); err != nil {
+		return err
+	}
+	if s[0] == '-' {
+		return ErrDashPrefixedKey
+	}
+	return nil
+}
+
+//qrEncoding only uses characters from the QR alphanumeric set
+var qrEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+const qrChecksumLen = 4
+
+//FmtKeyQR formats a key as unpadded uppercase base32 with a trailing
+//checksum, which fits QR alphanumeric mode better than base64
+func FmtKeyQR(vk []byte) string {
+	sum := sha512.Sum512(vk)
+	return qrEncoding.EncodeToString(append(append([]byte{}, vk...), sum[:qrChecksumLen]...))
+}
+
+func UnFmtKeyQR(s string) ([]byte, error) {
+	rv, err := qrEncoding.DecodeString(strings.ToUpper(s))
+	if err != nil {
+		return nil, err
+	}
+	if len(rv) != 32+qrChecksumLen {
+		return nil, errors.New("Invalid length")
+	}
+	key := rv[:32]
+	sum := sha512.Sum512(key)
+	if string(sum[:qrChecksumLen]) != string(rv[32:]) {
+		return nil, errors.New("Invalid checksum")
+	}
+	return key, nil
+}
+
+func FmtSig(sig []byte) string {
+	return base64.URLEncoding.EncodeToString(sig)
+}
+func UnFmtSig(sig string) ([]byte, error) {
+	rv, err := base64.URLEncoding.DecodeString(sig)
+	if err != nil {
+		return nil, err
+	}
+	if len(rv) != 64 {
+		return nil, errors.New("Invalid length")
+	}
+	return rv, nil
+}
+
+//FmtSigs formats each signature with FmtSig
+func FmtSigs(sigs [][]byte) []string {
+	rv := make([]string, len(sigs))
+	for i, sig := range sigs {
+		rv[i] = FmtSig(sig)
+	}
+	return rv
+}
+
+//UnFmtSigs decodes each string with UnFmtSig, reporting the index of
+//the first one that fails
+func UnFmtSigs(sigs []string) ([][]byte, error) {
+	rv := make([][]byte, len(sigs))
+	for i, s := range sigs {
+		sig, err := UnFmtSig(s)
+		if err != nil {
+			return nil, fmt.Errorf("Signature %d: %v", i, err)
+		}
+		rv[i] = sig
+	}
+	return rv, nil
+}
+
+//Fingerprint returns a short printable identifier for a verifying key,
+//the base64 encoding of the first 12 bytes of its SHA-512 hash
+func Fingerprint(vk []byte) string {
+	sum := sha512.Sum512(vk)
+	return base64.URLEncoding.EncodeToString(sum[:12])
+}
+
+func FmtHash(hash []byte) string {
+	return base64.URLEncoding.EncodeToString(hash)
+}
+
+func UnFmtHash(hash string) ([]byte, error) {
+	rv, err := base64.URLEncoding.DecodeString(hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(rv) != 32 {
+		return nil, errors.New("Invalid length")
+	}
+	return rv, nil
+}
+
+//UnFmtDigest decodes a full 64 byte SHA-512 digest, as returned by
+//HashBlob and HashStream and formatted with FmtHash
+func UnFmtDigest(digest string) ([]byte, error) {
+	rv, err := base64.URLEncoding.DecodeString(digest)
+	if err != nil {
+		return nil, err
+	}
+	if len(rv) != 64 {
+		return nil, errors.New("Invalid length")
+	}
+	return rv, nil
+}
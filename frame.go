@@ -0,0 +1,66 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+//MaxFrameLen bounds the blob length ReadSigned will allocate for, so
+//that a hostile peer cannot make us reserve 4GB with one header
+const MaxFrameLen = 16 * 1024 * 1024
+
+//WriteSigned writes blob to w as [4 byte BE len][blob][64 byte sig]
+//and returns the number of bytes written
+func WriteSigned(w io.Writer, sk []byte, vk []byte, blob []byte) (int, error) {
+	if len(blob) > MaxFrameLen {
+		return 0, errors.New("Frame too large")
+	}
+	frame := make([]byte, 4+len(blob)+64)
+	binary.BigEndian.PutUint32(frame, uint32(len(blob)))
+	copy(frame[4:], blob)
+	if err := SignBlob(sk, vk, frame[4+len(blob):], blob); err != nil {
+		return 0, err
+	}
+	return w.Write(frame)
+}
+
+//ReadSigned reads one frame written by WriteSigned and reports whether
+//its signature is valid for vk. An error is only returned if the frame
+//could not be read.
+func ReadSigned(r io.Reader, vk []byte) (blob []byte, ok bool, err error) {
+	var hdr [4]byte
+	if _, err = io.ReadFull(r, hdr[:]); err != nil {
+		return nil, false, err
+	}
+	ln := binary.BigEndian.Uint32(hdr[:])
+	if ln > MaxFrameLen {
+		return nil, false, errors.New("Frame too large")
+	}
+	body := make([]byte, int(ln)+64)
+	if _, err = io.ReadFull(r, body); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, false, err
+	}
+	blob = body[:ln]
+	return blob, VerifyBlob(vk, body[ln:], blob), nil
+}
@@ -0,0 +1,8 @@
+module github.com/samkumar/bw2crypto
+
+go 1.24.0
+
+require (
+	filippo.io/edwards25519 v1.2.0
+	golang.org/x/text v0.22.0
+)
@@ -0,0 +1,80 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"hash"
+	"io"
+)
+
+//NewHasher returns the SHA-512 hash used by the signing path (the Hash
+//callbacks handed to the C code), for hashing objects too large to
+//hold in memory. Its Sum matches HashBlob over the same bytes no matter
+//how the writes are split.
+func NewHasher() hash.Hash {
+	return sha512.New()
+}
+
+//HashBlob returns the 64 byte SHA-512 digest of b. Format it with
+//FmtHash and read it back with UnFmtDigest.
+func HashBlob(b []byte) []byte {
+	sum := sha512.Sum512(b)
+	return sum[:]
+}
+
+//HashStream returns the digest of everything read from r until EOF
+func HashStream(r io.Reader) ([]byte, error) {
+	h := NewHasher()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+//HMACSHA512 returns the HMAC-SHA-512 of message under key, the same as
+//crypto/hmac with sha512.New
+func HMACSHA512(key []byte, message []byte) []byte {
+	m := hmac.New(sha512.New, key)
+	m.Write(message)
+	return m.Sum(nil)
+}
+
+func keyCommitment(salt []byte, vk []byte) []byte {
+	h := sha512.New()
+	h.Write(salt)
+	h.Write(vk)
+	return h.Sum(nil)
+}
+
+//CommitKey returns H(salt || vk) for a fresh random 32 byte salt. The
+//commitment can be published straight away, while the salt is kept
+//until the key is revealed and checked with OpenCommitment.
+func CommitKey(vk []byte) (commitment []byte, salt []byte) {
+	salt = make([]byte, 32)
+	rand.Read(salt)
+	return keyCommitment(salt, vk), salt
+}
+
+//OpenCommitment returns true if commitment was made by CommitKey for
+//vk with the given salt
+func OpenCommitment(commitment []byte, salt []byte, vk []byte) bool {
+	return hmac.Equal(commitment, keyCommitment(salt, vk))
+}
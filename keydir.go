@@ -0,0 +1,79 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//KeyFileResult is the outcome of validating one key file. Fingerprint
+//is set whenever the verifying key could be parsed, and Err is nil only
+//if the file holds a valid keypair.
+type KeyFileResult struct {
+	Path        string
+	Fingerprint string
+	Err         error
+}
+
+//ValidateKeyDir checks every .key file in dir. Each file must hold a
+//formatted signing key and verifying key separated by whitespace. An
+//error is only returned if dir itself can't be read.
+func ValidateKeyDir(dir string) ([]KeyFileResult, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var rv []KeyFileResult
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".key" {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		res := KeyFileResult{Path: path}
+		res.Fingerprint, res.Err = validateKeyFile(path)
+		rv = append(rv, res)
+	}
+	return rv, nil
+}
+
+func validateKeyFile(path string) (string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(contents))
+	if len(fields) != 2 {
+		return "", errors.New("Expected a signing key and a verifying key")
+	}
+	vk, err := UnFmtKey(fields[1])
+	if err != nil {
+		return "", err
+	}
+	fp := Fingerprint(vk)
+	sk, err := UnFmtKey(fields[0])
+	if err != nil {
+		return fp, err
+	}
+	if !CheckKeypair(sk, vk) {
+		return fp, errors.New("Signing key does not match verifying key")
+	}
+	return fp, nil
+}
@@ -0,0 +1,130 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"crypto/sha512"
+	"errors"
+)
+
+//LogSigner maintains a rolling SHA-512 chain over appended entries.
+//The head after n entries is H(head[n-1] || H(entry[n-1])), starting
+//from 64 zero bytes. Checkpoint signs the current head under
+//checkpointContext.
+type LogSigner struct {
+	heads  [][]byte
+	leaves [][]byte
+}
+
+//InclusionProof shows that an entry is committed to by a chain head.
+//Prior is the head before the entry was appended and Following is the
+//leaf hash of every entry appended after it, up to the checkpoint.
+type InclusionProof struct {
+	Prior     []byte
+	Following [][]byte
+}
+
+//checkpointContext keeps checkpoint signatures apart from signatures
+//over other 64 byte blobs, like HashBlob output, made with the same key
+const checkpointContext = "bw2crypto log checkpoint"
+
+func chainStep(head []byte, leaf []byte) []byte {
+	h := sha512.New()
+	h.Write(head)
+	h.Write(leaf)
+	return h.Sum(nil)
+}
+
+func leafHash(entry []byte) []byte {
+	rv := sha512.Sum512(entry)
+	return rv[:]
+}
+
+//Append adds an entry to the log and advances the chain head
+func (l *LogSigner) Append(entry []byte) {
+	leaf := leafHash(entry)
+	l.heads = append(l.heads, chainStep(l.Head(), leaf))
+	l.leaves = append(l.leaves, leaf)
+}
+
+//Len returns the number of entries appended so far
+func (l *LogSigner) Len() int {
+	return len(l.leaves)
+}
+
+//Head returns the current chain head
+func (l *LogSigner) Head() []byte {
+	return l.headAt(len(l.heads))
+}
+
+func (l *LogSigner) headAt(size int) []byte {
+	rv := make([]byte, 64)
+	if size > 0 {
+		copy(rv, l.heads[size-1])
+	}
+	return rv
+}
+
+//Checkpoint signs the current chain head and returns the head along
+//with the signature. sig is nil if the keys are malformed.
+func (l *LogSigner) Checkpoint(sk []byte, vk []byte) (rootHash []byte, sig []byte) {
+	rootHash = l.Head()
+	sig = make([]byte, 64)
+	if SignBlobWithContext(sk, vk, sig, rootHash, checkpointContext) != nil {
+		sig = nil
+	}
+	return
+}
+
+//VerifyCheckpoint returns true if sig is a valid signature by vk over
+//the given chain head
+func VerifyCheckpoint(vk []byte, rootHash []byte, sig []byte) bool {
+	if len(rootHash) != 64 || len(sig) != 64 || len(vk) != 32 {
+		return false
+	}
+	return VerifyBlobWithContext(vk, sig, rootHash, checkpointContext)
+}
+
+//Prove builds a proof that the entry at index is included in the chain
+//head taken when the log contained size entries
+func (l *LogSigner) Prove(index int, size int) (*InclusionProof, error) {
+	if size < 0 || size > len(l.leaves) {
+		return nil, errors.New("Invalid log size")
+	}
+	if index < 0 || index >= size {
+		return nil, errors.New("Index out of range")
+	}
+	following := make([][]byte, 0, size-index-1)
+	for _, leaf := range l.leaves[index+1 : size] {
+		following = append(following, append([]byte{}, leaf...))
+	}
+	return &InclusionProof{Prior: l.headAt(index), Following: following}, nil
+}
+
+//VerifyInclusion returns true if replaying entry and the proof from
+//the prior head arrives at rootHash
+func VerifyInclusion(entry []byte, rootHash []byte, proof *InclusionProof) bool {
+	if proof == nil || len(proof.Prior) != 64 {
+		return false
+	}
+	head := chainStep(proof.Prior, leafHash(entry))
+	for _, leaf := range proof.Following {
+		head = chainStep(head, leaf)
+	}
+	return string(head) == string(rootHash)
+}
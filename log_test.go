@@ -0,0 +1,43 @@
+package bw2crypto
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLogCheckpointInclusion(t *testing.T) {
+	sk, vk := GenerateKeypair()
+	l := &LogSigner{}
+	for i := 0; i < 10; i++ {
+		l.Append([]byte(fmt.Sprintf("entry %d", i)))
+	}
+	root, sig := l.Checkpoint(sk, vk)
+	if !VerifyCheckpoint(vk, root, sig) {
+		t.Fatal("checkpoint did not verify")
+	}
+	//A plain signature over the same 64 bytes is not a checkpoint
+	plain := make([]byte, 64)
+	SignBlob(sk, vk, plain, root)
+	if VerifyCheckpoint(vk, root, plain) {
+		t.Fatal("plain signature over the head verified as a checkpoint")
+	}
+	l.Append([]byte("entry 10"))
+	if VerifyCheckpoint(vk, l.Head(), sig) {
+		t.Fatal("checkpoint verified against a later head")
+	}
+	for i := 0; i < 10; i++ {
+		proof, err := l.Prove(i, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyInclusion([]byte(fmt.Sprintf("entry %d", i)), root, proof) {
+			t.Fatalf("entry %d not proven included", i)
+		}
+		if VerifyInclusion([]byte("bogus"), root, proof) {
+			t.Fatalf("bogus entry proven included at %d", i)
+		}
+	}
+	if _, err := l.Prove(10, 10); err == nil {
+		t.Fatal("expected error proving entry past checkpoint")
+	}
+}
@@ -0,0 +1,66 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+//mapBlob returns the canonical encoding of m that SignMap signs:
+//  uint64 BE entry count
+//  then for each key in ascending byte order
+//    uint64 BE len(key) || key || uint64 BE len(value) || value
+//A nil value and an empty value encode the same way.
+func mapBlob(m map[string][]byte) []byte {
+	keys := make([]string, 0, len(m))
+	size := 8
+	for k, v := range m {
+		keys = append(keys, k)
+		size += 16 + len(k) + len(v)
+	}
+	sort.Strings(keys)
+	rv := make([]byte, 0, size)
+	rv = binary.BigEndian.AppendUint64(rv, uint64(len(keys)))
+	for _, k := range keys {
+		rv = binary.BigEndian.AppendUint64(rv, uint64(len(k)))
+		rv = append(rv, k...)
+		rv = binary.BigEndian.AppendUint64(rv, uint64(len(m[k])))
+		rv = append(rv, m[k]...)
+	}
+	return rv
+}
+
+//SignMap signs the canonical encoding of m, so the signature does not
+//depend on map iteration order. It returns nil if the keys are
+//malformed.
+func SignMap(sk []byte, vk []byte, m map[string][]byte) []byte {
+	sig := make([]byte, 64)
+	if SignBlob(sk, vk, sig, mapBlob(m)) != nil {
+		return nil
+	}
+	return sig
+}
+
+//VerifyMap checks a signature made by SignMap over the same entries
+func VerifyMap(vk []byte, sig []byte, m map[string][]byte) bool {
+	if len(sig) != 64 {
+		return false
+	}
+	return VerifyBlob(vk, sig, mapBlob(m))
+}
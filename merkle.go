@@ -0,0 +1,114 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"errors"
+)
+
+//Key allowlists are committed to as a Merkle tree over SHA-512 with
+//  leaf = H(0x00 || vk)
+//  node = H(0x01 || min(l, r) || max(l, r))
+//Children are sorted before hashing so a proof is just the list of
+//sibling hashes from the leaf up, without left/right flags. A node
+//without a sibling is carried up to the next level unchanged.
+
+func merkleLeaf(vk []byte) []byte {
+	h := sha512.New()
+	h.Write([]byte{0})
+	h.Write(vk)
+	return h.Sum(nil)
+}
+
+func merkleNode(a []byte, b []byte) []byte {
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	h := sha512.New()
+	h.Write([]byte{1})
+	h.Write(a)
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+func merkleLevel(level [][]byte) [][]byte {
+	next := make([][]byte, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 == len(level) {
+			next = append(next, level[i])
+		} else {
+			next = append(next, merkleNode(level[i], level[i+1]))
+		}
+	}
+	return next
+}
+
+func merkleLeaves(vks [][]byte) [][]byte {
+	level := make([][]byte, len(vks))
+	for i, vk := range vks {
+		level[i] = merkleLeaf(vk)
+	}
+	return level
+}
+
+//MerkleRoot returns the root committing to the set of keys vks
+func MerkleRoot(vks [][]byte) ([]byte, error) {
+	if len(vks) == 0 {
+		return nil, errors.New("No keys")
+	}
+	level := merkleLeaves(vks)
+	for len(level) > 1 {
+		level = merkleLevel(level)
+	}
+	return level[0], nil
+}
+
+//MerkleProof returns the membership proof for vks[index]
+func MerkleProof(vks [][]byte, index int) ([][]byte, error) {
+	if index < 0 || index >= len(vks) {
+		return nil, errors.New("Index out of range")
+	}
+	var proof [][]byte
+	level := merkleLeaves(vks)
+	for len(level) > 1 {
+		if sib := index ^ 1; sib < len(level) {
+			proof = append(proof, level[sib])
+		}
+		level = merkleLevel(level)
+		index /= 2
+	}
+	return proof, nil
+}
+
+//VerifyMerkleMember returns true if proof shows vk is in the set
+//committed to by root, and sig is vk's signature over blob
+func VerifyMerkleMember(root []byte, vk []byte, proof [][]byte, sig []byte, blob []byte) bool {
+	if len(vk) != 32 || len(sig) != 64 {
+		return false
+	}
+	node := merkleLeaf(vk)
+	for _, sib := range proof {
+		node = merkleNode(node, sib)
+	}
+	if !bytes.Equal(node, root) {
+		return false
+	}
+	return VerifyBlob(vk, sig, blob)
+}
@@ -0,0 +1,43 @@
+package bw2crypto
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestVerifyMerkleMember(t *testing.T) {
+	for _, n := range []int{1, 2, 5, 8} {
+		var sks, vks [][]byte
+		for i := 0; i < n; i++ {
+			sk, vk := TestKeypair(fmt.Sprintf("member %d/%d", i, n))
+			sks = append(sks, sk)
+			vks = append(vks, vk)
+		}
+		root, err := MerkleRoot(vks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range vks {
+			proof, err := MerkleProof(vks, i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sig := make([]byte, 64)
+			SignBlob(sks[i], vks[i], sig, []byte("proposal"))
+			if !VerifyMerkleMember(root, vks[i], proof, sig, []byte("proposal")) {
+				t.Fatalf("member %d of %d rejected", i, n)
+			}
+			if VerifyMerkleMember(root, vks[i], proof, sig, []byte("other")) {
+				t.Fatalf("member %d of %d accepted a bad signature", i, n)
+			}
+		}
+	}
+	osk, ovk := TestKeypair("outsider")
+	_, vk := TestKeypair("insider")
+	root, _ := MerkleRoot([][]byte{vk})
+	sig := make([]byte, 64)
+	SignBlob(osk, ovk, sig, []byte("proposal"))
+	if VerifyMerkleMember(root, ovk, nil, sig, []byte("proposal")) {
+		t.Fatal("outsider accepted")
+	}
+}
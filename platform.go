@@ -0,0 +1,46 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+//Signing backends, selected at build time:
+//  - cgo (default): ed25519-donna. First class on linux, where it links
+//    against OpenSSL for hashing and randomness, and on windows and
+//    darwin, where it is built with -DWINSUPPORT and calls back into Go
+//    for both. Needs a C toolchain and CGO_ENABLED=1.
+//  - purego (-tags purego): crypto/ed25519, anywhere Go runs.
+//  - edwards25519 (-tags edwards25519): filippo.io/edwards25519, anywhere
+//    Go runs.
+//Without cgo and without either tag the package still compiles, but
+//every signing operation fails with ErrUnsupportedPlatform. Functions
+//that can return an error return it, the rest return nil values, so
+//GenerateKeypair returns nil keys; use NewKeypair to get the error.
+
+import "errors"
+
+//ErrUnsupportedPlatform is returned when the package was built without
+//a backend
+var ErrUnsupportedPlatform = errors.New("No signing backend on this platform, build with cgo or -tags purego")
+
+//Supported returns ErrUnsupportedPlatform if this build has no signing
+//backend, and nil otherwise
+func Supported() error {
+	if !haveBackend {
+		return ErrUnsupportedPlatform
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+//Pure Go signing backends. By default the ed25519-donna C code is used
+//through cgo. Building with -tags purego uses crypto/ed25519 instead,
+//and -tags edwards25519 uses filippo.io/edwards25519 directly, which
+//avoids concatenating SignVector arguments. Both are always compiled
+//so the benchmarks can compare them against whichever backend is active.
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/subtle"
+
+	"filippo.io/edwards25519"
+)
+
+func generateKeypairWith(pub func(sk []byte) []byte) (sk []byte, vk []byte) {
+	sk = make([]byte, 32)
+	for {
+		rand.Read(sk)
+		vk = pub(sk)
+		if FmtKey(vk)[0] != '-' {
+			return
+		}
+	}
+}
+
+//loopVerifyBlob is the batch verifier for backends without a batch
+//primitive
+func loopVerifyBlob(verify func(vk []byte, sig []byte, blob []byte) bool, vks [][]byte, sigs [][]byte, blobs [][]byte) []bool {
+	rv := make([]bool, len(vks))
+	for i := range vks {
+		rv[i] = verify(vks[i], sigs[i], blobs[i])
+	}
+	return rv
+}
+
+//canonicalS reports whether the S half of a 64 byte sig is below the
+//group order. crypto/ed25519 and edwards25519 require that, while the
+//donna code only checks the top three bits, which lets S+L through.
+func canonicalS(sig []byte) bool {
+	_, err := edwards25519.NewScalar().SetCanonicalBytes(sig[32:])
+	return err == nil
+}
+
+func stdSignVector(sk []byte, vk []byte, into []byte, vec ...[]byte) error {
+	if err := checkSignArgs(sk, vk, into); err != nil {
+		return err
+	}
+	//crypto/ed25519 refuses a vk that does not match sk, so the key is
+	//rederived. A mismatched vk then yields a signature that fails to
+	//verify under it, as with the C code.
+	var msg []byte
+	for _, v := range vec {
+		msg = append(msg, v...)
+	}
+	copy(into, ed25519.Sign(ed25519.NewKeyFromSeed(sk), msg))
+	return nil
+}
+
+func stdVerifyBlob(vk []byte, sig []byte, blob []byte) bool {
+	if len(vk) != 32 || len(sig) != 64 {
+		return false
+	}
+	return ed25519.Verify(vk, blob, sig)
+}
+
+func stdVerifyVector(vk []byte, sig []byte, vec ...[]byte) bool {
+	var msg []byte
+	for _, v := range vec {
+		msg = append(msg, v...)
+	}
+	return stdVerifyBlob(vk, sig, msg)
+}
+
+func stdPublicKey(sk []byte) []byte {
+	return []byte(ed25519.NewKeyFromSeed(sk)[32:])
+}
+
+func edSignVector(sk []byte, vk []byte, into []byte, vec ...[]byte) error {
+	if err := checkSignArgs(sk, vk, into); err != nil {
+		return err
+	}
+	h := sha512.Sum512(sk)
+	s, _ := edwards25519.NewScalar().SetBytesWithClamping(h[:32])
+
+	//r = H(aExt[32..64], m)
+	mh := sha512.New()
+	mh.Write(h[32:])
+	for _, v := range vec {
+		mh.Write(v)
+	}
+	r, _ := edwards25519.NewScalar().SetUniformBytes(mh.Sum(nil))
+	R := new(edwards25519.Point).ScalarBaseMult(r)
+
+	//S = r + H(R,A,m)a
+	k := edHram(R.Bytes(), vk, vec)
+	S := edwards25519.NewScalar().MultiplyAdd(k, s, r)
+	copy(into[:32], R.Bytes())
+	copy(into[32:], S.Bytes())
+	return nil
+}
+
+func edVerifyVector(vk []byte, sig []byte, vec ...[]byte) bool {
+	if len(vk) != 32 || len(sig) != 64 {
+		return false
+	}
+	h := sha512.New()
+	h.Write(sig[:32])
+	h.Write(vk)
+	for _, v := range vec {
+		h.Write(v)
+	}
+	return edVerifyHram(vk, sig, h.Sum(nil))
+}
+
+//edVerifyHram checks sig given hram = H(R,A,m), which lets callers
+//stream m through the hash themselves
+func edVerifyHram(vk []byte, sig []byte, hram []byte) bool {
+	if len(vk) != 32 || len(sig) != 64 {
+		return false
+	}
+	A, err := new(edwards25519.Point).SetBytes(vk)
+	if err != nil {
+		return false
+	}
+	S, err := edwards25519.NewScalar().SetCanonicalBytes(sig[32:])
+	if err != nil {
+		return false
+	}
+	k, _ := edwards25519.NewScalar().SetUniformBytes(hram)
+	//check that R = SB - H(R,A,m)A
+	minusA := new(edwards25519.Point).Negate(A)
+	R := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(k, minusA, S)
+	return subtle.ConstantTimeCompare(sig[:32], R.Bytes()) == 1
+}
+
+func edHram(R []byte, vk []byte, vec [][]byte) *edwards25519.Scalar {
+	h := sha512.New()
+	h.Write(R)
+	h.Write(vk)
+	for _, v := range vec {
+		h.Write(v)
+	}
+	k, _ := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	return k
+}
+
+func edPublicKey(sk []byte) []byte {
+	h := sha512.Sum512(sk[:32])
+	s, _ := edwards25519.NewScalar().SetBytesWithClamping(h[:32])
+	return new(edwards25519.Point).ScalarBaseMult(s).Bytes()
+}
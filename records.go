@@ -0,0 +1,82 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"errors"
+	"io"
+)
+
+//RecordSpec locates one signed record inside a larger file: MsgLen
+//bytes of message at Offset and a 64 byte signature at SigOffset
+type RecordSpec struct {
+	Offset    int64
+	MsgLen    int
+	SigOffset int64
+}
+
+//VerifyRecordFile reads each record's message and signature from r and
+//verifies it. vks holds either one key per record or a single key for
+//all of them. Only one record is held in memory at a time, and each is
+//checked with VerifyBlob. That is what BatchVerifyBlob does for every
+//item, so nothing would be gained by reading records in batches. An
+//error is returned if a record can't be read, otherwise the result for
+//each record is reported in order.
+func VerifyRecordFile(r io.ReaderAt, records []RecordSpec, vks [][]byte) ([]bool, error) {
+	if len(vks) != 1 && len(vks) != len(records) {
+		return nil, errors.New("Need one key, or one key per record")
+	}
+	rv := make([]bool, len(records))
+	sig := make([]byte, 64)
+	var msg []byte
+	for i, rec := range records {
+		if rec.MsgLen < 0 {
+			return nil, errors.New("Invalid record length")
+		}
+		if cap(msg) < rec.MsgLen {
+			msg = make([]byte, rec.MsgLen)
+		}
+		msg = msg[:rec.MsgLen]
+		if err := readFullAt(r, msg, rec.Offset); err != nil {
+			return nil, err
+		}
+		if err := readFullAt(r, sig, rec.SigOffset); err != nil {
+			return nil, err
+		}
+		vk := vks[0]
+		if len(vks) > 1 {
+			vk = vks[i]
+		}
+		rv[i] = VerifyBlob(vk, sig, msg)
+	}
+	return rv, nil
+}
+
+//readFullAt fills buf from r at off. A ReaderAt may return io.EOF along
+//with a full read that ends at the end of the file, which is not an
+//error here.
+func readFullAt(r io.ReaderAt, buf []byte, off int64) error {
+	n, err := r.ReadAt(buf, off)
+	if n == len(buf) {
+		return nil
+	}
+	if err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
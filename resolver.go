@@ -0,0 +1,66 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import "errors"
+
+//KeyIDLen is the length of the key identifier prefixed to blobs
+//handled by SignWithKeyID and VerifyWithResolver
+const KeyIDLen = 32
+
+//ErrInvalidKeyIDLength is returned by SignWithKeyID for key ids that
+//are not KeyIDLen bytes long
+var ErrInvalidKeyIDLength = errors.New("keyID must be exactly 32 bytes long")
+
+//SignWithKeyID signs payload and returns keyID || sig || payload
+func SignWithKeyID(sk []byte, vk []byte, keyID []byte, payload []byte) ([]byte, error) {
+	if len(keyID) != KeyIDLen {
+		return nil, ErrInvalidKeyIDLength
+	}
+	rv := make([]byte, KeyIDLen+64+len(payload))
+	copy(rv, keyID)
+	if err := SignBlob(sk, vk, rv[KeyIDLen:KeyIDLen+64], payload); err != nil {
+		return nil, err
+	}
+	copy(rv[KeyIDLen+64:], payload)
+	return rv, nil
+}
+
+//VerifyWithResolver splits a keyID || sig || payload blob, asks resolve
+//for the verifying key matching keyID and checks the signature over
+//the payload. An error is returned if the blob is malformed or the
+//resolver fails, otherwise ok reports whether the signature is valid.
+func VerifyWithResolver(signedBlob []byte, resolve func(keyID []byte) ([]byte, error)) (payload []byte, ok bool, err error) {
+	if len(signedBlob) < KeyIDLen+64 {
+		return nil, false, errors.New("Signed blob too short")
+	}
+	keyID := signedBlob[:KeyIDLen]
+	sig := signedBlob[KeyIDLen : KeyIDLen+64]
+	payload = signedBlob[KeyIDLen+64:]
+	vk, err := resolve(keyID)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(vk) != 32 {
+		return nil, false, errors.New("Invalid length")
+	}
+	if !VerifyBlob(vk, sig, payload) {
+		return nil, false, nil
+	}
+	return payload, true, nil
+}
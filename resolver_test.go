@@ -0,0 +1,63 @@
+package bw2crypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"testing"
+)
+
+func TestVerifyWithResolver(t *testing.T) {
+	sk, vk := TestKeypair("resolver")
+	_, other := TestKeypair("resolver other")
+	id := sha256.Sum256(vk)
+	keys := map[string][]byte{string(id[:]): vk}
+	resolve := func(keyID []byte) ([]byte, error) {
+		if k, ok := keys[string(keyID)]; ok {
+			return k, nil
+		}
+		return nil, errors.New("unknown key")
+	}
+
+	for _, payload := range [][]byte{[]byte("payload"), nil} {
+		signed, err := SignWithKeyID(sk, vk, id[:], payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok, err := VerifyWithResolver(signed, resolve)
+		if err != nil || !ok || !bytes.Equal(got, payload) {
+			t.Fatalf("%q did not round trip: ok=%v err=%v", payload, ok, err)
+		}
+	}
+
+	signed, _ := SignWithKeyID(sk, vk, id[:], []byte("payload"))
+	for _, off := range []int{0, KeyIDLen + 5, len(signed) - 1} {
+		tampered := append([]byte{}, signed...)
+		tampered[off] ^= 0x01
+		_, ok, err := VerifyWithResolver(tampered, resolve)
+		if ok {
+			t.Fatalf("verified with byte %d flipped", off)
+		}
+		//A changed key id no longer resolves, the rest fail verification
+		if (off < KeyIDLen) != (err != nil) {
+			t.Fatalf("byte %d flipped: unexpected error %v", off, err)
+		}
+	}
+
+	resolverErr := errors.New("directory unavailable")
+	if _, _, err := VerifyWithResolver(signed, func([]byte) ([]byte, error) { return nil, resolverErr }); err != resolverErr {
+		t.Fatalf("resolver error not propagated: %v", err)
+	}
+	if _, ok, err := VerifyWithResolver(signed, func([]byte) ([]byte, error) { return other, nil }); ok || err != nil {
+		t.Fatalf("verified under the wrong key: ok=%v err=%v", ok, err)
+	}
+	if _, _, err := VerifyWithResolver(signed[:KeyIDLen+63], resolve); err == nil {
+		t.Fatal("expected error for a truncated blob")
+	}
+	if _, err := SignWithKeyID(sk, vk, id[:31], nil); err != ErrInvalidKeyIDLength {
+		t.Fatalf("expected ErrInvalidKeyIDLength, got %v", err)
+	}
+	if _, err := SignWithKeyID(sk[:31], vk, id[:], nil); err != ErrInvalidKeyLength {
+		t.Fatalf("expected ErrInvalidKeyLength, got %v", err)
+	}
+}
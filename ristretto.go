@@ -0,0 +1,169 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+//Ristretto255 (RFC 9496) is a prime order group built from the curve:
+//it takes the even points 2E and identifies those that differ by a
+//point of order four, so each ristretto255 element stands for four
+//curve points. Exactly one of the four lies in the prime order subgroup
+//generated by the base point, where every key from GenerateKeypair
+//lives. So:
+//  - ToRistretto only accepts keys in the prime order subgroup. A key
+//    with a small order (torsion) component is rejected, as it would
+//    otherwise share its encoding with the torsion free key.
+//  - FromRistretto returns the prime order point of the element, so
+//    FromRistretto(ToRistretto(vk)) == vk for every key it accepts
+//  - ristretto255 encodings are not ed25519 keys and must not be passed
+//    to VerifyBlob or SignBlob directly
+
+import (
+	"errors"
+	"math/big"
+
+	"filippo.io/edwards25519"
+	"filippo.io/edwards25519/field"
+)
+
+var (
+	ristrettoD              = fieldFromDecimal("37095705934669439343138083508754565189542113879843219016388785533085940283555")
+	ristrettoSqrtM1         = fieldFromDecimal("19681161376707505956807079304988542015446066515923890162744021073123829784752")
+	ristrettoInvSqrtAMinusD = fieldFromDecimal("54469307008909316920995813868745141605393597292927456921205312896311721017578")
+)
+
+//invCofactor is 1/8 mod L
+var invCofactor = func() *edwards25519.Scalar {
+	eight := make([]byte, 32)
+	eight[0] = 8
+	s, _ := edwards25519.NewScalar().SetCanonicalBytes(eight)
+	return s.Invert(s)
+}()
+
+//primeOrderPart returns the component of p in the prime order subgroup,
+//(1/8 mod L)(8p). Multiplying by the cofactor removes any torsion.
+func primeOrderPart(p *edwards25519.Point) *edwards25519.Point {
+	q := new(edwards25519.Point).MultByCofactor(p)
+	return q.ScalarMult(invCofactor, q)
+}
+
+func fieldFromDecimal(s string) *field.Element {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("bad field constant")
+	}
+	be := n.FillBytes(make([]byte, 32))
+	le := make([]byte, 32)
+	for i := range be {
+		le[i] = be[31-i]
+	}
+	fe, err := new(field.Element).SetBytes(le)
+	if err != nil {
+		panic(err)
+	}
+	return fe
+}
+
+//ToRistretto returns the ristretto255 encoding of the point vk, which
+//must be in the prime order subgroup
+func ToRistretto(vk []byte) ([]byte, error) {
+	if len(vk) != 32 {
+		return nil, errors.New("Invalid length")
+	}
+	p, err := new(edwards25519.Point).SetBytes(vk)
+	if err != nil {
+		return nil, errors.New("Invalid point")
+	}
+	if primeOrderPart(p).Equal(p) != 1 {
+		return nil, errors.New("Point has a small order component")
+	}
+	x0, y0, z0, t0 := p.ExtendedCoordinates()
+
+	one := new(field.Element).One()
+	u1 := new(field.Element).Add(z0, y0)
+	u1.Multiply(u1, new(field.Element).Subtract(z0, y0))
+	u2 := new(field.Element).Multiply(x0, y0)
+
+	v := new(field.Element).Square(u2)
+	v.Multiply(v, u1)
+	invsqrt, _ := new(field.Element).SqrtRatio(one, v)
+
+	den1 := new(field.Element).Multiply(invsqrt, u1)
+	den2 := new(field.Element).Multiply(invsqrt, u2)
+	zInv := new(field.Element).Multiply(den1, den2)
+	zInv.Multiply(zInv, t0)
+
+	ix0 := new(field.Element).Multiply(x0, ristrettoSqrtM1)
+	iy0 := new(field.Element).Multiply(y0, ristrettoSqrtM1)
+	enchanted := new(field.Element).Multiply(den1, ristrettoInvSqrtAMinusD)
+
+	rotate := new(field.Element).Multiply(t0, zInv).IsNegative()
+	x := new(field.Element).Select(iy0, x0, rotate)
+	y := new(field.Element).Select(ix0, y0, rotate)
+	denInv := new(field.Element).Select(enchanted, den2, rotate)
+
+	negY := new(field.Element).Negate(y)
+	y.Select(negY, y, new(field.Element).Multiply(x, zInv).IsNegative())
+
+	s := new(field.Element).Subtract(z0, y)
+	s.Multiply(s, denInv)
+	s.Absolute(s)
+	return s.Bytes(), nil
+}
+
+//FromRistretto decodes a ristretto255 encoding and returns the ed25519
+//encoding of its point in the prime order subgroup
+func FromRistretto(r []byte) ([]byte, error) {
+	if len(r) != 32 {
+		return nil, errors.New("Invalid length")
+	}
+	s, err := new(field.Element).SetBytes(r)
+	if err != nil || string(s.Bytes()) != string(r) || s.IsNegative() == 1 {
+		return nil, errors.New("Non canonical encoding")
+	}
+
+	one := new(field.Element).One()
+	ss := new(field.Element).Square(s)
+	u1 := new(field.Element).Subtract(one, ss)
+	u2 := new(field.Element).Add(one, ss)
+	u2Sqr := new(field.Element).Square(u2)
+
+	//v = -(d * u1^2) - u2^2
+	v := new(field.Element).Square(u1)
+	v.Multiply(v, ristrettoD)
+	v.Negate(v)
+	v.Subtract(v, u2Sqr)
+
+	invsqrt, wasSquare := new(field.Element).SqrtRatio(one, new(field.Element).Multiply(v, u2Sqr))
+	denX := new(field.Element).Multiply(invsqrt, u2)
+	denY := new(field.Element).Multiply(invsqrt, denX)
+	denY.Multiply(denY, v)
+
+	x := new(field.Element).Multiply(s, denX)
+	x.Add(x, x)
+	x.Absolute(x)
+	y := new(field.Element).Multiply(u1, denY)
+	t := new(field.Element).Multiply(x, y)
+
+	if wasSquare == 0 || t.IsNegative() == 1 || y.Equal(new(field.Element).Zero()) == 1 {
+		return nil, errors.New("Invalid encoding")
+	}
+	p, err := new(edwards25519.Point).SetExtendedCoordinates(x, y, one, t)
+	if err != nil {
+		return nil, err
+	}
+	return primeOrderPart(p).Bytes(), nil
+}
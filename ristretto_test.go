@@ -0,0 +1,79 @@
+package bw2crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+//Encodings of small multiples of the generator, RFC 9496 appendix A.1
+var ristrettoMultiples = []string{
+	"0000000000000000000000000000000000000000000000000000000000000000",
+	"e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76",
+	"6a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919",
+	"94741f5d5d52755ece4f23f044ee27d5d1ea1e2bd196b462166b16152a9d0259",
+	"da80862773358b466ffadfe0b3293ab3d9fd53c5ea6c955358f568322daf6a57",
+	"e882b131016b52c1d3337080187cf768423efccbb517bb495ab812c4160ff44e",
+}
+
+func TestRistrettoVectors(t *testing.T) {
+	p := edwards25519.NewIdentityPoint()
+	for i, want := range ristrettoMultiples {
+		enc, err := ToRistretto(p.Bytes())
+		if err != nil {
+			t.Fatalf("%dB: %v", i, err)
+		}
+		if hex.EncodeToString(enc) != want {
+			t.Fatalf("%dB: got %x want %s", i, enc, want)
+		}
+		dec, err := FromRistretto(enc)
+		if err != nil {
+			t.Fatalf("%dB: decode: %v", i, err)
+		}
+		if string(dec) != string(p.Bytes()) {
+			t.Fatalf("%dB: decoded to %x", i, dec)
+		}
+		p.Add(p, edwards25519.NewGeneratorPoint())
+	}
+}
+
+func TestRistrettoKeys(t *testing.T) {
+	_, vk := TestKeypair("ristretto")
+	enc, err := ToRistretto(vk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec, err := FromRistretto(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(dec) != string(vk) {
+		t.Fatal("FromRistretto did not return the original key")
+	}
+	//Keys that differ from vk only by torsion are rejected rather than
+	//sharing its encoding
+	p, _ := new(edwards25519.Point).SetBytes(vk)
+	for _, torsion := range []string{
+		"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", //order 2
+		"0000000000000000000000000000000000000000000000000000000000000080", //order 4
+		"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a", //order 8
+	} {
+		enc, _ := hex.DecodeString(torsion)
+		T, err := new(edwards25519.Point).SetBytes(enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ToRistretto(T.Bytes()); err == nil {
+			t.Fatalf("small order point %s accepted", torsion)
+		}
+		if _, err := ToRistretto(new(edwards25519.Point).Add(p, T).Bytes()); err == nil {
+			t.Fatalf("key plus %s accepted", torsion)
+		}
+	}
+	//RFC 9496 A.2: the encoding of -1 as a field element is invalid
+	bad, _ := hex.DecodeString("ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	if _, err := FromRistretto(bad); err == nil {
+		t.Fatal("non canonical encoding accepted")
+	}
+}
@@ -0,0 +1,83 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import "fmt"
+
+//rotationTag is prefixed to the new key so a rotation signature can't
+//be confused with a signature over an arbitrary 32 byte blob
+var rotationTag = []byte("bw2crypto key rotation\x00")
+
+//RotationLink hands trust from the previous key in a chain to NewVk.
+//Sig is the previous key's signature as made by SignRotation.
+type RotationLink struct {
+	NewVk []byte
+	Sig   []byte
+}
+
+//BrokenLinkError reports which link of a rotation chain failed
+type BrokenLinkError struct {
+	Index int
+}
+
+func (e *BrokenLinkError) Error() string {
+	return fmt.Sprintf("Rotation link %d does not verify", e.Index)
+}
+
+func rotationBlob(newVk []byte) []byte {
+	return append(append([]byte{}, rotationTag...), newVk...)
+}
+
+//SignRotation signs newVk with the old keypair, endorsing it as the
+//successor of oldVk. It returns nil if the old keys are malformed.
+func SignRotation(oldSk []byte, oldVk []byte, newVk []byte) []byte {
+	sig := make([]byte, 64)
+	if SignBlob(oldSk, oldVk, sig, rotationBlob(newVk)) != nil {
+		return nil
+	}
+	return sig
+}
+
+//VerifyRotation returns true if sig is oldVk's endorsement of newVk
+func VerifyRotation(oldVk []byte, newVk []byte, sig []byte) bool {
+	if len(oldVk) != 32 || len(newVk) != 32 || len(sig) != 64 {
+		return false
+	}
+	return VerifyBlob(oldVk, sig, rotationBlob(newVk))
+}
+
+//CheckRotationChain follows chain from rootVk and returns the final
+//key, or a *BrokenLinkError naming the first link that fails
+func CheckRotationChain(rootVk []byte, chain []RotationLink) ([]byte, error) {
+	vk := rootVk
+	for i, link := range chain {
+		if !VerifyRotation(vk, link.NewVk, link.Sig) {
+			return nil, &BrokenLinkError{Index: i}
+		}
+		vk = link.NewVk
+	}
+	return vk, nil
+}
+
+//VerifyRotationChain returns the final key of the chain if every link
+//verifies starting from rootVk. Use CheckRotationChain to find out
+//which link is broken.
+func VerifyRotationChain(rootVk []byte, chain []RotationLink) (finalVk []byte, ok bool) {
+	finalVk, err := CheckRotationChain(rootVk, chain)
+	return finalVk, err == nil
+}
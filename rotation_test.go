@@ -0,0 +1,31 @@
+package bw2crypto
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRotationChain(t *testing.T) {
+	sk, vk := TestKeypair("root")
+	root := vk
+	var chain []RotationLink
+	for i := 0; i < 4; i++ {
+		nsk, nvk := TestKeypair(fmt.Sprintf("rotated %d", i))
+		chain = append(chain, RotationLink{NewVk: nvk, Sig: SignRotation(sk, vk, nvk)})
+		sk, vk = nsk, nvk
+	}
+	final, ok := VerifyRotationChain(root, chain)
+	if !ok || string(final) != string(vk) {
+		t.Fatal("valid chain did not verify to the last key")
+	}
+
+	_, evil := TestKeypair("evil")
+	chain[2].NewVk = evil
+	_, err := CheckRotationChain(root, chain)
+	if be, ok := err.(*BrokenLinkError); !ok || be.Index != 2 {
+		t.Fatalf("expected broken link 2, got %v", err)
+	}
+	if _, ok := VerifyRotationChain(root, chain); ok {
+		t.Fatal("tampered chain verified")
+	}
+}
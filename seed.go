@@ -0,0 +1,54 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import "errors"
+
+//ErrDashPrefixedKey is returned alongside the keypair by
+//GenerateKeypairFromSeed, and by ValidateKey, when the formatted
+//verifying key starts with '-', which breaks command line tools that
+//take keys as arguments
+var ErrDashPrefixedKey = errors.New("Verifying key formats with a leading '-'")
+
+//NewKeypair is GenerateKeypair, but returns ErrUnsupportedPlatform when
+//built without a signing backend instead of nil keys
+func NewKeypair() (sk []byte, vk []byte, err error) {
+	if err := Supported(); err != nil {
+		return nil, nil, err
+	}
+	sk, vk = GenerateKeypair()
+	return sk, vk, nil
+}
+
+//GenerateKeypairFromSeed expands a 32 byte seed into a keypair. The same
+//seed always gives the same keypair. GenerateKeypair draws fresh keys
+//until the verifying key does not format with a leading '-', which is
+//not possible for a fixed seed, so instead the keypair is returned as
+//usual together with ErrDashPrefixedKey. The keys are still valid, and
+//callers that don't pass keys on command lines may ignore the error.
+func GenerateKeypairFromSeed(seed []byte) (sk []byte, vk []byte, err error) {
+	vk, err = DeriveVerifyingKey(seed)
+	if err != nil {
+		return nil, nil, err
+	}
+	sk = append([]byte{}, seed...)
+	if FmtKey(vk)[0] == '-' {
+		return sk, vk, ErrDashPrefixedKey
+	}
+	return sk, vk, nil
+}
@@ -0,0 +1,142 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+)
+
+//Shares produced by SplitKey are 33 bytes: a non zero x coordinate
+//followed by the 32 y values of the byte-wise polynomials over GF(2^8)
+//(AES polynomial) evaluated at x
+const ShareLen = 33
+
+//gfMul multiplies in GF(2^8) without data dependent branches
+func gfMul(a, b byte) byte {
+	var p byte
+	for i := 0; i < 8; i++ {
+		p ^= -(b & 1) & a
+		hi := -(a >> 7)
+		a = (a << 1) ^ (hi & 0x1b)
+		b >>= 1
+	}
+	return p
+}
+
+//gfInv returns a^254, the inverse of a for a != 0
+func gfInv(a byte) byte {
+	rv := byte(1)
+	for i := 0; i < 7; i++ {
+		a = gfMul(a, a)
+		rv = gfMul(rv, a)
+	}
+	return rv
+}
+
+//SplitKey splits the signing key sk into parts shares, any threshold
+//of which recombine to sk with CombineKey
+func SplitKey(sk []byte, parts int, threshold int) ([][]byte, error) {
+	if len(sk) != 32 {
+		return nil, errors.New("Invalid length")
+	}
+	if threshold < 2 || threshold > parts || parts > 255 {
+		return nil, errors.New("Need 2 <= threshold <= parts <= 255")
+	}
+	//coeffs[i] holds the i'th coefficient of each byte's polynomial,
+	//coeffs[0] is the secret itself
+	coeffs := make([][]byte, threshold)
+	coeffs[0] = sk
+	for i := 1; i < threshold; i++ {
+		coeffs[i] = make([]byte, 32)
+		if _, err := rand.Read(coeffs[i]); err != nil {
+			return nil, err
+		}
+	}
+	shares := make([][]byte, parts)
+	for p := 0; p < parts; p++ {
+		x := byte(p + 1)
+		share := make([]byte, ShareLen)
+		share[0] = x
+		for b := 0; b < 32; b++ {
+			//Horner's rule from the highest coefficient down
+			var y byte
+			for i := threshold - 1; i >= 0; i-- {
+				y = gfMul(y, x) ^ coeffs[i][b]
+			}
+			share[1+b] = y
+		}
+		shares[p] = share
+	}
+	for i := 1; i < threshold; i++ {
+		Wipe(coeffs[i])
+	}
+	return shares, nil
+}
+
+//CombineKey recovers a signing key from shares made by SplitKey. It can
+//not tell how many shares are needed, so passing fewer than the split
+//threshold silently yields the wrong key; check the result against the
+//known verifying key with CheckKeypair.
+func CombineKey(shares [][]byte) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("No shares")
+	}
+	seen := make(map[byte]bool)
+	for _, s := range shares {
+		if len(s) != ShareLen {
+			return nil, errors.New("Invalid share length")
+		}
+		if s[0] == 0 || seen[s[0]] {
+			return nil, errors.New("Invalid or duplicate share")
+		}
+		seen[s[0]] = true
+	}
+	sk := make([]byte, 32)
+	for i, si := range shares {
+		//Lagrange basis polynomial for share i evaluated at 0. In
+		//GF(2^8) subtraction is xor, so (0 - xj)/(xi - xj) = xj/(xi^xj)
+		l := byte(1)
+		for j, sj := range shares {
+			if i == j {
+				continue
+			}
+			l = gfMul(l, gfMul(sj[0], gfInv(si[0]^sj[0])))
+		}
+		for b := 0; b < 32; b++ {
+			sk[b] ^= gfMul(l, si[1+b])
+		}
+	}
+	return sk, nil
+}
+
+func FmtShare(share []byte) string {
+	return base64.URLEncoding.EncodeToString(share)
+}
+
+func UnFmtShare(share string) ([]byte, error) {
+	rv, err := base64.URLEncoding.DecodeString(share)
+	if err != nil {
+		return nil, err
+	}
+	if len(rv) != ShareLen {
+		return nil, errors.New("Invalid length")
+	}
+	return rv, nil
+}
@@ -0,0 +1,39 @@
+package bw2crypto
+
+import "testing"
+
+func TestSplitCombineKey(t *testing.T) {
+	sk, vk := TestKeypair("custodians")
+	shares, err := SplitKey(sk, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subsets := [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}}
+	for _, sub := range subsets {
+		var picked [][]byte
+		for _, i := range sub {
+			s, err := UnFmtShare(FmtShare(shares[i]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			picked = append(picked, s)
+		}
+		rv, err := CombineKey(picked)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(rv) != string(sk) || !CheckKeypair(rv, vk) {
+			t.Fatalf("shares %v did not recombine to the key", sub)
+		}
+	}
+	rv, _ := CombineKey(shares[:2])
+	if string(rv) == string(sk) {
+		t.Fatal("two shares recovered a threshold 3 key")
+	}
+	if _, err := CombineKey([][]byte{shares[0], shares[0], shares[1]}); err == nil {
+		t.Fatal("expected error for duplicate shares")
+	}
+	if _, err := SplitKey(sk, 2, 3); err == nil {
+		t.Fatal("expected error for threshold > parts")
+	}
+}
@@ -0,0 +1,130 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+//ErrMessageTooLarge is returned by VerifyBlobMaxLen for blobs longer
+//than the allowed maximum
+var ErrMessageTooLarge = errors.New("Message too large")
+
+//ErrInvalidKeyLength is returned by the signing functions if sk or vk
+//is not 32 bytes long, and by UnFmtKey for strings that don't decode
+//to 32 bytes
+var ErrInvalidKeyLength = errors.New("Keys must be exactly 32 bytes long")
+
+//ErrInvalidSigLength is returned by SignBlob and SignVector if the
+//output buffer is not exactly 64 bytes long
+var ErrInvalidSigLength = errors.New("Into must be exactly 64 bytes long")
+
+//checkSignArgs validates the buffers handed to SignBlob and SignVector
+//before any backend indexes into them
+func checkSignArgs(sk []byte, vk []byte, into []byte) error {
+	if len(sk) != 32 || len(vk) != 32 {
+		return ErrInvalidKeyLength
+	}
+	if len(into) != 64 {
+		return ErrInvalidSigLength
+	}
+	return nil
+}
+
+//SignBlobContext signs blob and returns the signature, or ctx.Err() if
+//the context is already done. Signing itself is not interruptible.
+func SignBlobContext(ctx context.Context, sk []byte, vk []byte, blob []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sig := make([]byte, 64)
+	if err := SignBlob(sk, vk, sig, blob); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+//VerifyBlobMaxLen is VerifyBlob but returns ErrMessageTooLarge without
+//hashing anything if blob is longer than maxLen bytes
+func VerifyBlobMaxLen(vk []byte, sig []byte, blob []byte, maxLen int) (bool, error) {
+	if len(blob) > maxLen {
+		return false, ErrMessageTooLarge
+	}
+	return VerifyBlob(vk, sig, blob), nil
+}
+
+var expiryTag = []byte("bw2crypto expiry\x00")
+
+func expiryBlob(expiry []byte, blob []byte) []byte {
+	rv := append([]byte{}, expiryTag...)
+	rv = append(rv, expiry...)
+	return append(rv, blob...)
+}
+
+//SignWithExpiry binds expiry into the signature over blob and returns
+//the 8 byte BE unix expiry time followed by the 64 byte signature, or
+//nil if the keys are malformed
+func SignWithExpiry(sk []byte, vk []byte, blob []byte, expiry time.Time) []byte {
+	signed := make([]byte, 8+64)
+	binary.BigEndian.PutUint64(signed, uint64(expiry.Unix()))
+	if SignBlob(sk, vk, signed[8:], expiryBlob(signed[:8], blob)) != nil {
+		return nil
+	}
+	return signed
+}
+
+//VerifyWithExpiry checks the output of SignWithExpiry. ok is true only
+//if the signature is valid and the expiry time has not passed, expired
+//is true if the signature is valid but the expiry time has passed.
+func VerifyWithExpiry(vk []byte, signed []byte, blob []byte) (ok bool, expired bool) {
+	if len(signed) != 8+64 {
+		return false, false
+	}
+	if !VerifyBlob(vk, signed[8:], expiryBlob(signed[:8], blob)) {
+		return false, false
+	}
+	expiry := time.Unix(int64(binary.BigEndian.Uint64(signed[:8])), 0)
+	if !time.Now().Before(expiry) {
+		return false, true
+	}
+	return true, false
+}
+
+//SignAudited signs blob and also returns the signer's Fingerprint, for
+//logging who signed what. If signing fails only the error is returned,
+//so nothing can be logged as signed.
+func SignAudited(sk []byte, vk []byte, blob []byte) (sig []byte, signerFingerprint string, err error) {
+	sig = make([]byte, 64)
+	if err := SignBlob(sk, vk, sig, blob); err != nil {
+		return nil, "", err
+	}
+	return sig, Fingerprint(vk), nil
+}
+
+//MustVerify panics if sig is not vk's signature over blob. It is meant
+//for data that is signed by us and must always be valid, such as
+//embedded configuration, and must never be used on untrusted input.
+func MustVerify(vk []byte, sig []byte, blob []byte) {
+	if len(vk) != 32 || len(sig) != 64 || !VerifyBlob(vk, sig, blob) {
+		panic(fmt.Sprintf("bw2crypto: signature over %d byte blob does not verify for key %s", len(blob), Fingerprint(vk)))
+	}
+}
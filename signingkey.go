@@ -0,0 +1,90 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+//SigningKey wraps a keypair and counts how many signatures it has made,
+//so a runaway or compromised signer can be noticed in-process
+type SigningKey struct {
+	sk   []byte
+	vk   []byte
+	uses uint64
+
+	hookLock sync.Mutex
+	hooks    []*thresholdHook
+}
+
+type thresholdHook struct {
+	n     uint64
+	cb    func()
+	fired bool
+}
+
+func NewSigningKey(sk []byte, vk []byte) *SigningKey {
+	return &SigningKey{sk: sk, vk: vk}
+}
+
+//VerifyingKey returns the verifying key of the wrapped keypair
+func (k *SigningKey) VerifyingKey() []byte {
+	return k.vk
+}
+
+//Sign signs blob, counts the use and runs any threshold callbacks that
+//this use pushed over their limit. It returns nil, without counting a
+//use, if the wrapped keys are malformed.
+func (k *SigningKey) Sign(blob []byte) []byte {
+	sig := make([]byte, 64)
+	if SignBlob(k.sk, k.vk, sig, blob) != nil {
+		return nil
+	}
+	uses := atomic.AddUint64(&k.uses, 1)
+
+	var due []func()
+	k.hookLock.Lock()
+	for _, h := range k.hooks {
+		if !h.fired && uses > h.n {
+			h.fired = true
+			due = append(due, h.cb)
+		}
+	}
+	k.hookLock.Unlock()
+	for _, cb := range due {
+		cb()
+	}
+	return sig
+}
+
+//UseCount returns the number of signatures made with this key
+func (k *SigningKey) UseCount() uint64 {
+	return atomic.LoadUint64(&k.uses)
+}
+
+//OnThreshold registers cb to be called once, from the Sign call that
+//takes the use count above n
+func (k *SigningKey) OnThreshold(n int, cb func()) {
+	if n < 0 {
+		n = 0
+	}
+	k.hookLock.Lock()
+	k.hooks = append(k.hooks, &thresholdHook{n: uint64(n), cb: cb})
+	k.hookLock.Unlock()
+}
@@ -0,0 +1,73 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+)
+
+//ErrKeypairMismatch is returned when a verifying key does not belong to
+//the signing key it is paired with
+var ErrKeypairMismatch = errors.New("Verifying key does not match signing key")
+
+//ToStdPublicKey returns vk as a crypto/ed25519 public key. The encoding
+//is the same, so this only checks the length and copies.
+func ToStdPublicKey(vk []byte) (ed25519.PublicKey, error) {
+	if len(vk) != 32 {
+		return nil, ErrInvalidKeyLength
+	}
+	return ed25519.PublicKey(append([]byte{}, vk...)), nil
+}
+
+//ToStdPrivateKey returns the crypto/ed25519 private key for a keypair.
+//crypto/ed25519 stores seed || public key, where this package keeps the
+//32 byte seed as sk and vk separately, so vk is checked against sk.
+func ToStdPrivateKey(sk []byte, vk []byte) (ed25519.PrivateKey, error) {
+	if len(sk) != 32 || len(vk) != 32 {
+		return nil, ErrInvalidKeyLength
+	}
+	priv := ed25519.NewKeyFromSeed(sk)
+	if !bytes.Equal(priv[32:], vk) {
+		return nil, ErrKeypairMismatch
+	}
+	return priv, nil
+}
+
+//FromStdPublicKey returns a crypto/ed25519 public key as a vk
+func FromStdPublicKey(pub ed25519.PublicKey) ([]byte, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, ErrInvalidKeyLength
+	}
+	return append([]byte{}, pub...), nil
+}
+
+//FromStdPrivateKey splits a crypto/ed25519 private key into sk and vk.
+//An error is returned if the public half does not match the seed.
+func FromStdPrivateKey(priv ed25519.PrivateKey) (sk []byte, vk []byte, err error) {
+	if len(priv) != ed25519.PrivateKeySize {
+		return nil, nil, ErrInvalidKeyLength
+	}
+	sk = append([]byte{}, priv.Seed()...)
+	vk = append([]byte{}, priv[32:]...)
+	if !bytes.Equal(ed25519.NewKeyFromSeed(sk)[32:], vk) {
+		return nil, nil, ErrKeypairMismatch
+	}
+	return sk, vk, nil
+}
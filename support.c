@@ -1,2 +1,4 @@
+//go:build !purego && !edwards25519
+// +build !purego,!edwards25519
 
 #include "ed25519.h"
@@ -0,0 +1,200 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"archive/tar"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"sort"
+)
+
+//TarSignatureName is the member appended by SignTar
+const TarSignatureName = ".signature"
+
+//tarDigest accumulates the canonical digest of a tar stream. For every
+//member, in order, it hashes the header fields that affect how the
+//member is extracted followed by the size and hash of its content:
+//  str(name) || typeflag || str(linkname) || u64(mode) || u64(uid) ||
+//  u64(gid) || str(uname) || str(gname) || u64(mtime s) ||
+//  u64(mtime ns) || u64(devmajor) || u64(devminor) || u64(#pax) ||
+//  [str(key) || str(value)]* || u64(size) || H(content)
+//where str(s) is uint32 BE len(s) || s, u64 is a uint64 BE and the PAX
+//records are the ones not already covered by a field above, sorted by
+//key. The final digest is H of that sequence.
+type tarDigest struct {
+	h hash.Hash
+}
+
+//tarFieldRecords are PAX records that only restate a header field, or
+//are times we don't sign, so they are left out of the digest
+var tarFieldRecords = map[string]bool{
+	"path": true, "linkpath": true, "size": true, "uid": true,
+	"gid": true, "uname": true, "gname": true, "mtime": true,
+	"atime": true, "ctime": true,
+}
+
+func newTarDigest() *tarDigest {
+	return &tarDigest{h: sha512.New()}
+}
+
+func (d *tarDigest) str(s string) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(len(s)))
+	d.h.Write(b[:])
+	d.h.Write([]byte(s))
+}
+
+func (d *tarDigest) u64(v int64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v))
+	d.h.Write(b[:])
+}
+
+//add copies the current member content from tr into w (if non nil)
+//while folding it into the digest
+func (d *tarDigest) add(hdr *tar.Header, tr io.Reader, w io.Writer) error {
+	ch := sha512.New()
+	dst := io.Writer(ch)
+	if w != nil {
+		dst = io.MultiWriter(ch, w)
+	}
+	n, err := io.Copy(dst, tr)
+	if err != nil {
+		return err
+	}
+	d.str(hdr.Name)
+	d.h.Write([]byte{hdr.Typeflag})
+	d.str(hdr.Linkname)
+	d.u64(hdr.Mode)
+	d.u64(int64(hdr.Uid))
+	d.u64(int64(hdr.Gid))
+	d.str(hdr.Uname)
+	d.str(hdr.Gname)
+	d.u64(hdr.ModTime.Unix())
+	d.u64(int64(hdr.ModTime.Nanosecond()))
+	d.u64(hdr.Devmajor)
+	d.u64(hdr.Devminor)
+	var keys []string
+	for k := range hdr.PAXRecords {
+		if !tarFieldRecords[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	d.u64(int64(len(keys)))
+	for _, k := range keys {
+		d.str(k)
+		d.str(hdr.PAXRecords[k])
+	}
+	d.u64(n)
+	d.h.Write(ch.Sum(nil))
+	return nil
+}
+
+func (d *tarDigest) sum() []byte {
+	return d.h.Sum(nil)
+}
+
+//SignTar copies the tar stream in r to w and appends a .signature
+//member holding the formatted signature over the canonical digest of
+//the copied members
+func SignTar(sk []byte, vk []byte, r io.Reader, w io.Writer) error {
+	tr := tar.NewReader(r)
+	tw := tar.NewWriter(w)
+	d := newTarDigest()
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Name == TarSignatureName {
+			return errors.New("Archive is already signed")
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if err := d.add(hdr, tr, tw); err != nil {
+			return err
+		}
+	}
+	sig := make([]byte, 64)
+	if err := SignBlob(sk, vk, sig, d.sum()); err != nil {
+		return err
+	}
+	body := []byte(FmtSig(sig))
+	err := tw.WriteHeader(&tar.Header{
+		Name:     TarSignatureName,
+		Typeflag: tar.TypeReg,
+		Mode:     0644,
+		Size:     int64(len(body)),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := tw.Write(body); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+//VerifyTar checks that the final member of the tar stream is a
+//.signature over the preceding members made by vk. An error is
+//returned if the archive cannot be read or carries no signature.
+func VerifyTar(vk []byte, r io.Reader) (bool, error) {
+	tr := tar.NewReader(r)
+	d := newTarDigest()
+	var sig []byte
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return false, err
+		}
+		if sig != nil {
+			//Members after the signature are not covered by it
+			return false, nil
+		}
+		if hdr.Name == TarSignatureName {
+			body, err := io.ReadAll(io.LimitReader(tr, 128))
+			if err != nil {
+				return false, err
+			}
+			sig, err = UnFmtSig(string(body))
+			if err != nil {
+				return false, err
+			}
+			continue
+		}
+		if err := d.add(hdr, tr, nil); err != nil {
+			return false, err
+		}
+	}
+	if sig == nil {
+		return false, errors.New("Archive is not signed")
+	}
+	return VerifyBlob(vk, sig, d.sum()), nil
+}
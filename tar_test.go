@@ -0,0 +1,135 @@
+package bw2crypto
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func buildTar(t *testing.T, files map[string]string, order []string) []byte {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for _, name := range order {
+		body := files[name]
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(body))}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(body))
+	}
+	tw.Close()
+	return buf.Bytes()
+}
+
+func TestSignVerifyTar(t *testing.T) {
+	sk, vk := GenerateKeypair()
+	files := map[string]string{"a.txt": "hello", "b.bin": "world", "empty": ""}
+	order := []string{"a.txt", "b.bin", "empty"}
+	signed := &bytes.Buffer{}
+	if err := SignTar(sk, vk, bytes.NewReader(buildTar(t, files, order)), signed); err != nil {
+		t.Fatal(err)
+	}
+	ok, err := VerifyTar(vk, bytes.NewReader(signed.Bytes()))
+	if err != nil || !ok {
+		t.Fatalf("signed archive did not verify: %v", err)
+	}
+
+	_, other := GenerateKeypair()
+	if ok, _ := VerifyTar(other, bytes.NewReader(signed.Bytes())); ok {
+		t.Fatal("archive verified under the wrong key")
+	}
+
+	tampered := bytes.Replace(signed.Bytes(), []byte("world"), []byte("w0rld"), 1)
+	if ok, _ := VerifyTar(vk, bytes.NewReader(tampered)); ok {
+		t.Fatal("tampered archive verified")
+	}
+
+	if _, err := VerifyTar(vk, bytes.NewReader(buildTar(t, files, order))); err == nil {
+		t.Fatal("expected error for unsigned archive")
+	}
+}
+
+//rewriteTar copies a tar stream, passing every header through edit
+func rewriteTar(t *testing.T, archive []byte, edit func(hdr *tar.Header)) []byte {
+	buf := &bytes.Buffer{}
+	tr := tar.NewReader(bytes.NewReader(archive))
+	tw := tar.NewWriter(buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		edit(hdr)
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	return buf.Bytes()
+}
+
+func TestVerifyTarHeaderFields(t *testing.T) {
+	sk, vk := TestKeypair("tar headers")
+	src := &bytes.Buffer{}
+	tw := tar.NewWriter(src)
+	tw.WriteHeader(&tar.Header{Name: "bin/tool", Mode: 0755, Size: 4, Uid: 1000, Gid: 1000,
+		Uname: "build", Gname: "build", ModTime: time.Unix(1500000000, 0),
+		PAXRecords: map[string]string{"BW2CRYPTO.origin": "ci"}})
+	tw.Write([]byte("tool"))
+	tw.WriteHeader(&tar.Header{Name: "bin/link", Typeflag: tar.TypeSymlink, Linkname: "tool", Mode: 0777})
+	tw.Close()
+	signed := &bytes.Buffer{}
+	if err := SignTar(sk, vk, src, signed); err != nil {
+		t.Fatal(err)
+	}
+	same := rewriteTar(t, signed.Bytes(), func(hdr *tar.Header) {})
+	if ok, err := VerifyTar(vk, bytes.NewReader(same)); err != nil || !ok {
+		t.Fatalf("unmodified copy did not verify: %v", err)
+	}
+
+	edits := map[string]func(hdr *tar.Header){
+		"linkname": func(hdr *tar.Header) {
+			if hdr.Name == "bin/link" {
+				hdr.Linkname = "/etc/passwd"
+			}
+		},
+		"mode": func(hdr *tar.Header) {
+			if hdr.Name == "bin/tool" {
+				hdr.Mode |= 04000
+			}
+		},
+		"uid": func(hdr *tar.Header) {
+			if hdr.Name == "bin/tool" {
+				hdr.Uid = 0
+			}
+		},
+		"gname": func(hdr *tar.Header) {
+			if hdr.Name == "bin/tool" {
+				hdr.Gname = "wheel"
+			}
+		},
+		"mtime": func(hdr *tar.Header) {
+			if hdr.Name == "bin/tool" {
+				hdr.ModTime = hdr.ModTime.Add(time.Hour)
+			}
+		},
+		"pax": func(hdr *tar.Header) {
+			if hdr.Name == "bin/tool" {
+				hdr.PAXRecords["BW2CRYPTO.origin"] = "laptop"
+			}
+		},
+	}
+	for name, edit := range edits {
+		tampered := rewriteTar(t, signed.Bytes(), edit)
+		if ok, _ := VerifyTar(vk, bytes.NewReader(tampered)); ok {
+			t.Fatalf("archive with changed %s verified", name)
+		}
+	}
+}
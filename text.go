@@ -0,0 +1,40 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import "golang.org/x/text/unicode/norm"
+
+//SignText signs the NFC normalization of s, so the same text entered
+//with precomposed or decomposed characters gives the same signature.
+//It returns nil if the keys are malformed.
+func SignText(sk []byte, vk []byte, s string) []byte {
+	sig := make([]byte, 64)
+	if SignBlob(sk, vk, sig, norm.NFC.Bytes([]byte(s))) != nil {
+		return nil
+	}
+	return sig
+}
+
+//VerifyText checks a signature from SignText against the NFC
+//normalization of s
+func VerifyText(vk []byte, sig []byte, s string) bool {
+	if len(sig) != 64 {
+		return false
+	}
+	return VerifyBlob(vk, sig, norm.NFC.Bytes([]byte(s)))
+}
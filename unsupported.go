@@ -0,0 +1,60 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+//go:build !cgo && !purego && !edwards25519
+// +build !cgo,!purego,!edwards25519
+
+package bw2crypto
+
+const haveBackend = false
+
+func SignVector(sk []byte, vk []byte, into []byte, vec ...[]byte) error {
+	return ErrUnsupportedPlatform
+}
+
+func SignBlob(sk []byte, vk []byte, into []byte, blob []byte) error {
+	return ErrUnsupportedPlatform
+}
+
+//VerifyBlob always returns false, as nothing can be verified
+func VerifyBlob(vk []byte, sig []byte, blob []byte) bool {
+	return false
+}
+
+//VerifyVector always returns false, as nothing can be verified
+func VerifyVector(vk []byte, sig []byte, vec ...[]byte) bool {
+	return false
+}
+
+func verifyHram(vk []byte, sig []byte, hram []byte) bool {
+	return false
+}
+
+func batchVerifyBlob(vks [][]byte, sigs [][]byte, blobs [][]byte) []bool {
+	return make([]bool, len(vks))
+}
+
+//GenerateKeypair returns nil keys, as it has no way to return an error.
+//NewKeypair returns ErrUnsupportedPlatform instead.
+func GenerateKeypair() (sk []byte, vk []byte) {
+	return nil, nil
+}
+
+//publicKey is only reached by callers that skipped checking Supported
+func publicKey(sk []byte) []byte {
+	panic(ErrUnsupportedPlatform)
+}
@@ -0,0 +1,72 @@
+//go:build !cgo && !purego && !edwards25519
+// +build !cgo,!purego,!edwards25519
+
+package bw2crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestUnsupportedErrors(t *testing.T) {
+	if err := Supported(); err != ErrUnsupportedPlatform {
+		t.Fatalf("Supported returned %v", err)
+	}
+	key := bytes.Repeat([]byte{1}, 32)
+	sig := make([]byte, 64)
+	if err := SignBlob(key, key, sig, nil); err != ErrUnsupportedPlatform {
+		t.Fatalf("SignBlob returned %v", err)
+	}
+	if err := SignVector(key, key, sig, []byte("a")); err != ErrUnsupportedPlatform {
+		t.Fatalf("SignVector returned %v", err)
+	}
+	if VerifyBlob(key, sig, nil) || VerifyVector(key, sig) {
+		t.Fatal("verified without a backend")
+	}
+	if _, _, err := NewKeypair(); err != ErrUnsupportedPlatform {
+		t.Fatalf("NewKeypair returned %v", err)
+	}
+	if err := GenerateKeypairInto(make([]byte, 32), make([]byte, 32)); err != ErrUnsupportedPlatform {
+		t.Fatalf("GenerateKeypairInto returned %v", err)
+	}
+	if _, _, err := GenerateKeypairFromSeed(key); err != ErrUnsupportedPlatform {
+		t.Fatalf("GenerateKeypairFromSeed returned %v", err)
+	}
+	if _, err := DeriveVerifyingKey(key); err != ErrUnsupportedPlatform {
+		t.Fatalf("DeriveVerifyingKey returned %v", err)
+	}
+	if _, _, err := DeriveMessageKey(key, []byte("id")); err != ErrUnsupportedPlatform {
+		t.Fatalf("DeriveMessageKey returned %v", err)
+	}
+	if _, err := SelfTestVectors(); err != ErrUnsupportedPlatform {
+		t.Fatalf("SelfTestVectors returned %v", err)
+	}
+	if _, err := BatchVerifyBlob([][]byte{key}, [][]byte{sig}, [][]byte{nil}); err != ErrUnsupportedPlatform {
+		t.Fatalf("BatchVerifyBlob returned %v", err)
+	}
+}
+
+func TestUnsupportedNilResults(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	if CheckKeypair(key, key) {
+		t.Fatal("CheckKeypair succeeded without a backend")
+	}
+	if _, _, _, ok := DetectSwappedKeypair(key, key); ok {
+		t.Fatal("DetectSwappedKeypair succeeded without a backend")
+	}
+	if sk, vk := TestKeypair("stub"); sk != nil || vk != nil {
+		t.Fatal("TestKeypair returned keys without a backend")
+	}
+	if vk, sig := DeterministicSign([]byte("stub"), nil); vk != nil || sig != nil {
+		t.Fatal("DeterministicSign returned a signature without a backend")
+	}
+	if vk, proof, sig := SignWithMessageKey(key, key, []byte("id"), nil); vk != nil || proof != nil || sig != nil {
+		t.Fatal("SignWithMessageKey returned a signature without a backend")
+	}
+	if sk, vk := GenerateKeypair(); sk != nil || vk != nil {
+		t.Fatal("GenerateKeypair returned keys without a backend")
+	}
+	if sk, vk, err := NewKeypair(); err != ErrUnsupportedPlatform || sk != nil || vk != nil {
+		t.Fatalf("NewKeypair returned %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"hash"
+)
+
+//StreamingVectorVerifier checks a SignVector signature one field at a
+//time. SignVector signs the plain concatenation of its fields in order
+//with no lengths or separators, so fields must be added in the same
+//order, and only the concatenated bytes matter: ("ab", "c") verifies
+//against a signature over ("a", "bc").
+//
+//The hash being checked starts with the signature's R and the verifying
+//key, so the zero value has to keep every field until Verify is called.
+//A verifier from NewStreamingVectorVerifier already knows both and
+//hashes each field as it is added without keeping it.
+type StreamingVectorVerifier struct {
+	fields [][]byte
+	vk     []byte
+	sig    []byte
+	h      hash.Hash
+}
+
+//NewStreamingVectorVerifier returns a verifier that hashes fields as
+//they arrive. Verify must then be called with the same vk and sig.
+func NewStreamingVectorVerifier(vk []byte, sig []byte) *StreamingVectorVerifier {
+	v := &StreamingVectorVerifier{
+		vk:  append([]byte{}, vk...),
+		sig: append([]byte{}, sig...),
+		h:   sha512.New(),
+	}
+	if len(sig) == 64 {
+		v.h.Write(sig[:32])
+	}
+	v.h.Write(vk)
+	return v
+}
+
+//AddField appends the next field of the vector
+func (v *StreamingVectorVerifier) AddField(b []byte) {
+	if v.h != nil {
+		v.h.Write(b)
+		return
+	}
+	v.fields = append(v.fields, append([]byte{}, b...))
+}
+
+//Verify returns true if sig is vk's SignVector signature over the
+//fields added so far
+func (v *StreamingVectorVerifier) Verify(vk []byte, sig []byte) bool {
+	if len(vk) != 32 || len(sig) != 64 {
+		return false
+	}
+	if v.h == nil {
+		return VerifyVector(vk, sig, v.fields...)
+	}
+	if !bytes.Equal(vk, v.vk) || !bytes.Equal(sig, v.sig) {
+		return false
+	}
+	return verifyHram(vk, sig, v.h.Sum(nil))
+}
@@ -0,0 +1,151 @@
+package bw2crypto
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestStreamingVectorVerifier(t *testing.T) {
+	sk, vk := TestKeypair("vector stream")
+	fields := [][]byte{[]byte("header"), []byte("uri/path"), []byte("payload bytes")}
+	sig := make([]byte, 64)
+	SignVector(sk, vk, sig, fields...)
+
+	buffered := &StreamingVectorVerifier{}
+	streamed := NewStreamingVectorVerifier(vk, sig)
+	for _, f := range fields {
+		buffered.AddField(f)
+		streamed.AddField(f)
+	}
+	if !buffered.Verify(vk, sig) || !streamed.Verify(vk, sig) {
+		t.Fatal("SignVector signature did not verify field by field")
+	}
+
+	//Only the concatenation is signed
+	resplit := &StreamingVectorVerifier{}
+	resplit.AddField([]byte("head"))
+	resplit.AddField([]byte("eruri/pathpayload bytes"))
+	if !resplit.Verify(vk, sig) {
+		t.Fatal("resplit fields did not verify")
+	}
+
+	reordered := &StreamingVectorVerifier{}
+	reordered.AddField(fields[1])
+	reordered.AddField(fields[0])
+	reordered.AddField(fields[2])
+	if reordered.Verify(vk, sig) {
+		t.Fatal("reordered fields verified")
+	}
+
+	_, other := TestKeypair("other")
+	if streamed.Verify(other, sig) {
+		t.Fatal("streamed verifier accepted a different key")
+	}
+}
+
+func TestVerifyVectorRoundTrip(t *testing.T) {
+	sk, vk := TestKeypair("vector verify")
+	vec := [][]byte{[]byte("first"), []byte("middle"), []byte("last")}
+	sig := make([]byte, 64)
+	SignVector(sk, vk, sig, vec...)
+	if !VerifyVector(vk, sig, vec...) {
+		t.Fatal("SignVector signature did not verify")
+	}
+	vec[1][2] ^= 0x01
+	if VerifyVector(vk, sig, vec...) {
+		t.Fatal("verified with a flipped byte in the middle element")
+	}
+	vec[1][2] ^= 0x01
+	if VerifyVector(vk, sig, vec[1], vec[0], vec[2]) {
+		t.Fatal("verified with reordered elements")
+	}
+}
+
+func TestSignVectorEmptyElements(t *testing.T) {
+	sk, vk := TestKeypair("vector empty")
+	empty := []byte{}
+	vecs := [][][]byte{
+		{empty, []byte("b"), []byte("c")},
+		{[]byte("a"), empty, []byte("c")},
+		{[]byte("a"), []byte("b"), empty},
+		{empty, nil, empty},
+	}
+	for i, vec := range vecs {
+		sig := make([]byte, 64)
+		if err := SignVector(sk, vk, sig, vec...); err != nil {
+			t.Fatalf("vector %d: %v", i, err)
+		}
+		if !VerifyVector(vk, sig, vec...) {
+			t.Fatalf("vector %d with an empty element did not verify", i)
+		}
+		if !VerifyBlob(vk, sig, bytes.Join(vec, nil)) {
+			t.Fatalf("vector %d is not a signature over the concatenation", i)
+		}
+	}
+}
+
+//TestStreamingMatchesBackend checks that VerifyHeaderBody and
+//StreamingVectorVerifier, which hash the message themselves, accept and
+//reject exactly what VerifyBlob does for the golden vectors and for
+//malformed signatures and keys around the edge cases implementations
+//tend to disagree on
+func TestStreamingMatchesBackend(t *testing.T) {
+	vecs, err := SelfTestVectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	flip := func(b []byte, i int, mask byte) []byte {
+		rv := append([]byte{}, b...)
+		rv[i] ^= mask
+		return rv
+	}
+	identity := append([]byte{1}, make([]byte, 31)...)
+	nonCanonicalY := append(bytes.Repeat([]byte{0xff}, 31), 0x7f)
+
+	type tcase struct {
+		name         string
+		vk, sig, msg []byte
+	}
+	var cases []tcase
+	for i, v := range vecs {
+		cases = append(cases,
+			tcase{fmt.Sprintf("golden %d", i), v.Vk, v.Sig, v.Message},
+			tcase{fmt.Sprintf("golden %d flipped R", i), v.Vk, flip(v.Sig, 3, 0x10), v.Message},
+			tcase{fmt.Sprintf("golden %d flipped S", i), v.Vk, flip(v.Sig, 40, 0x01), v.Message},
+			tcase{fmt.Sprintf("golden %d S+L", i), v.Vk, withSPlusL(v.Sig, 1), v.Message},
+			tcase{fmt.Sprintf("golden %d S high bit", i), v.Vk, flip(v.Sig, 63, 0x80), v.Message},
+			tcase{fmt.Sprintf("golden %d identity key", i), identity, v.Sig, v.Message},
+			tcase{fmt.Sprintf("golden %d non canonical key", i), nonCanonicalY, v.Sig, v.Message},
+		)
+	}
+	//With the identity as verifying key R = SB verifies any message
+	cases = append(cases,
+		tcase{"identity key, R = B", identity, append(append([]byte{0x58}, bytes.Repeat([]byte{0x66}, 31)...), append([]byte{1}, make([]byte, 31)...)...), []byte("anything")},
+		tcase{"zero signature", vecs[1].Vk, make([]byte, 64), vecs[1].Message},
+	)
+
+	for _, c := range cases {
+		want := VerifyBlob(c.vk, c.sig, c.msg)
+		if got := VerifyVector(c.vk, c.sig, c.msg); got != want {
+			t.Errorf("%s: VerifyVector %v, VerifyBlob %v", c.name, got, want)
+		}
+		split := len(c.msg) / 2
+		got, err := VerifyHeaderBody(c.vk, c.sig, c.msg[:split], bytes.NewReader(c.msg[split:]))
+		if err != nil || got != want {
+			t.Errorf("%s: VerifyHeaderBody %v (%v), VerifyBlob %v", c.name, got, err, want)
+		}
+		streamed := NewStreamingVectorVerifier(c.vk, c.sig)
+		buffered := &StreamingVectorVerifier{}
+		for _, f := range [][]byte{c.msg[:split], c.msg[split:]} {
+			streamed.AddField(f)
+			buffered.AddField(f)
+		}
+		if got := streamed.Verify(c.vk, c.sig); got != want {
+			t.Errorf("%s: streaming verifier %v, VerifyBlob %v", c.name, got, want)
+		}
+		if got := buffered.Verify(c.vk, c.sig); got != want {
+			t.Errorf("%s: buffering verifier %v, VerifyBlob %v", c.name, got, want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"crypto/sha512"
+	"fmt"
+)
+
+//SelfTestVector is one published (seed, message, vk, signature) tuple.
+//Seed is used directly as the 32 byte signing key.
+type SelfTestVector struct {
+	Seed    []byte
+	Message []byte
+	Vk      []byte
+	Sig     []byte
+}
+
+//selfTestLens are the message lengths covered, including the empty
+//message and lengths around the 128 byte SHA-512 block
+var selfTestLens = []int{0, 1, 32, 127, 128, 129, 300}
+
+//SelfTestVectors derives the published vectors. Vector i has seed
+//SHA-512("bw2crypto selftest <i>")[:32] and a message of
+//selfTestLens[i] bytes where byte j is (7j + i) mod 256. These are a
+//stable contract: the expected values are also asserted by the tests,
+//and printed by the selftest-vectors command. Without a signing
+//backend ErrUnsupportedPlatform is returned.
+func SelfTestVectors() ([]SelfTestVector, error) {
+	if err := Supported(); err != nil {
+		return nil, err
+	}
+	rv := make([]SelfTestVector, len(selfTestLens))
+	for i, ln := range selfTestLens {
+		seed := sha512.Sum512([]byte(fmt.Sprintf("bw2crypto selftest %d", i)))
+		v := SelfTestVector{Seed: seed[:32], Message: make([]byte, ln)}
+		for j := range v.Message {
+			v.Message[j] = byte(7*j + i)
+		}
+		v.Vk = publicKey(v.Seed)
+		v.Sig = make([]byte, 64)
+		SignBlob(v.Seed, v.Vk, v.Sig, v.Message)
+		rv[i] = v
+	}
+	return rv, nil
+}
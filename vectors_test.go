@@ -0,0 +1,52 @@
+package bw2crypto
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+)
+
+//selfTestGolden are the published verifying keys and signatures for
+//SelfTestVectors. Changing any of these breaks compatibility with
+//every implementation that tested against them.
+var selfTestGolden = []struct{ vk, sig string }{
+	{"33faa1d672c1491ca6027ef59509191023f4cf0b3be204420819f355b848254a",
+		"c3779dd6245dfc54ce2955fa30b2e4ba56d494268cac37f7531e889fcc66f2a198b25078045f1f6f870478450255a46b929993703de5568c458544acf22ded09"},
+	{"a12b1e6365d8bb654ffd6b16a2ee090dacc020b60ea5ff9642c5c09ac494188d",
+		"9ab7c166be21f7fb13f39104bcd22ece0b7da9e308a0aad7e59ab9ff2d0cde853321ea17452b1b58f46c3dcac6318ea7ca8da00e3d15929f33062bce79525003"},
+	{"560b4997a94c66571d1ff5d313eb92dbc1fd5f2dacdd95c61e15eec273f8c47e",
+		"fc700048eb123561d359cc40e45930578e17e52cc2dd152c65d3172e9a7a5b87c1be537fc09a6d33a695844ee8728ab6b4abc795bf877760187d34cc86a78c0e"},
+	{"e09b1004eea3bd4ac52f9b44c1ec3962e2dd335d52a1d170ef65e4a5994ab08d",
+		"9c5961fe224e27b7fd04f488604f49f73f737b2129d640f02273375102cdb2f886c9a32308f6b89c76dc3f8e3699bef07b229521fb66c1bf0efabebb3214680f"},
+	{"fb597533c79d4fa58ed69ca84867f97ba6a74a4f731f173d3d5b327782655932",
+		"7b01ae388e6ece1406a530710c0eb141c2763c0c24c9f1d1fed0ec09528fdde186cfab2bbd5252dde9a9972628afa14115a237732efe2bc9ad7cef688a11de0a"},
+	{"07536e0315c82ff76cc8898b734a633305ac6a9b7e7db1b0137603efdf5d996d",
+		"7998f168041c8ba0f8fccbf9d47cafd20a7001c1c5b0c418565c58d814d0ba23c1793c216313a65597b1a01ecf71c9c4be678ac41045deaa9274564bc85c9c05"},
+	{"bae2d2124d0974a9f8d13f398572f9e97a84d92ceefb4fdbe7649b2cbe75519c",
+		"485b781912e3f70fd5ed3319c6eb4047209a38b3b4d6edb754933d4983e635ce2f2cef042f5a0b74d6a1e45c9d842b8c3f7df77b38ca3ae5a251e24a36d89d09"},
+}
+
+func TestSelfTestVectors(t *testing.T) {
+	vecs, err := SelfTestVectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vecs) != len(selfTestGolden) {
+		t.Fatalf("%d vectors, %d golden values", len(vecs), len(selfTestGolden))
+	}
+	for i, v := range vecs {
+		if hex.EncodeToString(v.Vk) != selfTestGolden[i].vk {
+			t.Errorf("vector %d: vk %x", i, v.Vk)
+		}
+		if hex.EncodeToString(v.Sig) != selfTestGolden[i].sig {
+			t.Errorf("vector %d: sig %x", i, v.Sig)
+		}
+		if len(v.Message) != selfTestLens[i] || !VerifyBlob(v.Vk, v.Sig, v.Message) {
+			t.Errorf("vector %d does not verify", i)
+		}
+		std := ed25519.NewKeyFromSeed(v.Seed)
+		if hex.EncodeToString(ed25519.Sign(std, v.Message)) != selfTestGolden[i].sig {
+			t.Errorf("vector %d disagrees with crypto/ed25519", i)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// This file is part of BOSSWAVE.
+//
+// BOSSWAVE is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// BOSSWAVE is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with BOSSWAVE.  If not, see <http://www.gnu.org/licenses/>.
+//
+// Copyright © 2015 Michael Andersen <m.andersen@cs.berkeley.edu>
+
+package bw2crypto
+
+import (
+	"crypto/rand"
+	"runtime"
+)
+
+//Wipe overwrites b with zeroes, for clearing signing keys and other
+//secrets once they are no longer needed. This is best effort only: the
+//Go runtime may have copied the buffer when growing a slice or moving a
+//stack, and nothing can reach those copies. Keep secrets in buffers
+//allocated once at their final size, such as those handed to
+//GenerateKeypairInto, to avoid making copies in the first place.
+func Wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+	//Keeps the stores above from being treated as dead
+	runtime.KeepAlive(b)
+}
+
+//GenerateKeypairInto is GenerateKeypair writing into caller provided 32
+//byte buffers, so the caller controls where the signing key lives and
+//can Wipe it when done
+func GenerateKeypairInto(skBuf []byte, vkBuf []byte) error {
+	if err := Supported(); err != nil {
+		return err
+	}
+	if len(skBuf) != 32 || len(vkBuf) != 32 {
+		return ErrInvalidKeyLength
+	}
+	for {
+		if _, err := rand.Read(skBuf); err != nil {
+			return err
+		}
+		copy(vkBuf, publicKey(skBuf))
+		if FmtKey(vkBuf)[0] != '-' {
+			return nil
+		}
+	}
+}